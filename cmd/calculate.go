@@ -1,21 +1,67 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/martoc/semver/core"
-	"github.com/martoc/semver/logger"
 	"github.com/spf13/cobra"
 )
 
 func init() {
-	calculateCmd.Flags().StringP("path", "p", ".", "Path to a git repository")
+	addCalculationFlags(calculateCmd)
 	calculateCmd.Flags().BoolP("push", "u", false, "Push the new tag to the remote repository")
 	calculateCmd.Flags().BoolP("add-floating-tags", "f", false,
 		"Add the floating tags to the new tag for example v1.2.3 will also add v1 and v1.2")
+	calculateCmd.Flags().Bool("add-latest-tag", false,
+		"Move the latest tag to the new tag when it is the greatest stable release")
+	calculateCmd.Flags().Bool("add-stable-tag", false,
+		"Move the stable tag to the new tag when it is the greatest stable release")
 	calculateCmd.Flags().BoolP("disable-tagging", "d", false, "Disable tagging")
+	calculateCmd.Flags().Bool("require-clean", false, "Fail instead of tagging a working tree with uncommitted changes")
+}
+
+// addCalculationFlags adds the flags of the settings the next version is calculated with to the command.
+func addCalculationFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("path", "p", ".", "Path to a git repository")
+	cmd.Flags().String("repo", "",
+		"URL of a remote repository to clone into memory instead of using the repository at path")
+	cmd.Flags().Bool("deepen", false,
+		"Fetch more history from the remote when a shallow clone has no release tag")
+	cmd.Flags().Bool("first-parent", false,
+		"Only analyze the first parent of each commit, so that merged branches count as their merge commit")
+	cmd.Flags().Bool("merge-aware", false,
+		"Analyze the first parent of each commit and the commits each merge merged, ignoring their tags")
+	cmd.Flags().Bool("fetch", false, "Fetch the tags of the remote before calculating the version")
+	cmd.Flags().Bool("prune", false, "Fetch the tags of the remote and delete local tags deleted from the remote")
+	cmd.Flags().String("branch", "",
+		"Branch name used to match branch rules, defaults to the branch at HEAD or the CI environment")
+	cmd.Flags().StringArray("branch-rule", []string{},
+		"Release rule for branches matching a pattern, for example 'develop:prerelease=beta' or "+
+			"'feature/*:prerelease=feat-{slug},push=false' or 'release/1.x:line=1.x', the first match wins")
+	cmd.Flags().StringArray("remote", []string{},
+		"Remote to push tags to, repeatable for mirrors, the first one is also used for fetching (default origin)")
+	cmd.Flags().String("line", "",
+		"Version line to release within, for example 1.x, 1.4.x or '>=1.0.0 <2.0.0'")
+	cmd.Flags().String("base-tag", "",
+		"Tag the next version is calculated from: greatest-reachable (default), nearest or greatest")
+	cmd.Flags().Bool("tolerant-tags", false,
+		"Parse legacy tags such as 1.2, v1 or v01.02.3 as versions, reporting the normalized and ignored tags")
+	cmd.Flags().StringArray("tag-pattern", []string{},
+		"Regular expression capturing the version of legacy tags, for example '^release_(.+)$', repeatable")
+	cmd.Flags().StringArray("tag-include", []string{},
+		"Glob or /regular expression/ of the tags considered as releases, for example 'v*', repeatable")
+	cmd.Flags().StringArray("tag-exclude", []string{},
+		"Glob or /regular expression/ of the tags never considered as releases, for example 'nightly-*', repeatable")
+	cmd.Flags().String("tag-template", "",
+		"Tag template of the default stream with a {version} placeholder, for example release-{version} (default v{version})")
+	cmd.Flags().String("stream", "",
+		"Release stream with its own tags and versions, for example helm for helm-vX.Y.Z tags")
+	cmd.Flags().Duration("timeout", 0,
+		"Maximum duration of the calculation including fetching and pushing, for example 2m, no limit when zero")
 }
 
 var calculateCmd = &cobra.Command{
@@ -24,26 +70,127 @@ var calculateCmd = &cobra.Command{
 	Long: `Calculates a new semantic version based on the latest commit message in the repository
 		using semantic versioning and conventional commits (https://www.conventionalcommits.org/en/v1.0.0-beta.4/)`,
 	Run: func(cmd *cobra.Command, _ []string) {
-		path, _ := cmd.Flags().GetString("path")
-		push, _ := cmd.Flags().GetBool("push")
-		addFloatingTags, _ := cmd.Flags().GetBool("add-floating-tags")
-		disableTagging, _ := cmd.Flags().GetBool("disable-tagging")
-		result, err := core.NewCalculateCommandBuilder().
-			SetPath(path).
-			SetAddFloatingTags(addFloatingTags).
-			SetPush(push).
-			SetDisableTagging(disableTagging).
-			Build().
-			Execute()
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		builder := newCalculateBuilder(cmd)
+		ctx, cancel := withTimeout(cmd.Context(), timeout)
+		output, err := builder.BuildCalculateCommand().Calculate(ctx)
+		cancel()
 		if err != nil {
-			logger.GetInstance().Error(err)
-			os.Exit(1)
+			if output != nil {
+				printJSON(cmd, output) // Report the pushed and rolled back tags of the failed release
+			}
+			exitWithError(cmd, err)
 		}
-		jsonResult, err := json.Marshal(result) // Convert result to JSON
-		if err != nil {
-			logger.GetInstance().Error(err)
-			os.Exit(1)
+		printJSON(cmd, output)
+		if !output.Released {
+			exitWithError(cmd, fmt.Errorf("%w: no commit since %s requires a release", errNoRelease, output.NextVersion))
 		}
-		fmt.Fprintln(os.Stdout, string(jsonResult)) // Print JSON result
 	},
 }
+
+// newCalculateBuilder returns a calculate builder with the settings of the configuration file, the SEMVER_*
+// environment variables and the flags of the command. It exits when a setting is invalid.
+func newCalculateBuilder(cmd *cobra.Command) *core.CalculateCommandBuilder {
+	path, _ := cmd.Flags().GetString("path")
+	repositoryURL, _ := cmd.Flags().GetString("repo")
+	branch, _ := cmd.Flags().GetString("branch")
+	configPath := path
+	if repositoryURL != "" {
+		configPath = "" // A remote repository has no local configuration file to discover
+	}
+
+	config, _, err := loadConfig(cmd, configPath)
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	err = applyCalculateFlags(cmd, &config)
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	builder := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetRepositoryURL(repositoryURL).
+		SetBranch(branch)
+
+	err = config.Apply(builder)
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+
+	return builder
+}
+
+// applyCalculateFlags overrides the settings with the flags set on the command line,
+// which take precedence over the environment variables and the configuration file.
+func applyCalculateFlags(cmd *cobra.Command, config *core.Config) error {
+	for key, setting := range config.Bools() {
+		if cmd.Flags().Changed(key) {
+			*setting, _ = cmd.Flags().GetBool(key)
+		}
+	}
+
+	if cmd.Flags().Changed("remote") {
+		config.Remotes, _ = cmd.Flags().GetStringArray("remote")
+	}
+
+	if cmd.Flags().Changed("line") {
+		config.Line, _ = cmd.Flags().GetString("line")
+	}
+
+	if cmd.Flags().Changed("base-tag") {
+		config.BaseTag, _ = cmd.Flags().GetString("base-tag")
+	}
+
+	if cmd.Flags().Changed("tag-pattern") {
+		config.TagPatterns, _ = cmd.Flags().GetStringArray("tag-pattern")
+	}
+
+	if cmd.Flags().Changed("tag-include") {
+		config.TagInclude, _ = cmd.Flags().GetStringArray("tag-include")
+	}
+
+	if cmd.Flags().Changed("tag-exclude") {
+		config.TagExclude, _ = cmd.Flags().GetStringArray("tag-exclude")
+	}
+
+	if cmd.Flags().Changed("tag-template") {
+		config.TagTemplate, _ = cmd.Flags().GetString("tag-template")
+	}
+
+	if cmd.Flags().Changed("stream") {
+		config.Stream, _ = cmd.Flags().GetString("stream")
+	}
+
+	if cmd.Flags().Changed("branch-rule") {
+		values, _ := cmd.Flags().GetStringArray("branch-rule")
+
+		branchRules, err := core.ParseBranchRuleConfigs(values)
+		if err != nil {
+			return err
+		}
+
+		config.BranchRules = branchRules
+	}
+
+	return nil
+}
+
+// printJSON prints the result as JSON to the standard output.
+func printJSON(cmd *cobra.Command, result interface{}) {
+	jsonResult, err := json.Marshal(result) // Convert result to JSON
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+	fmt.Fprintln(os.Stdout, string(jsonResult)) // Print JSON result
+}
+
+// withTimeout returns a context canceled after the timeout, or only when canceled if the timeout is zero.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/martoc/semver/core"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const yamlIndent = 2
+
+func init() {
+	configCmd.PersistentFlags().StringP("path", "p", ".", "Path the configuration file is discovered from")
+	configPrintCmd.Flags().Bool("effective", false,
+		"Print the settings after applying the defaults and the SEMVER_* environment variables")
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configPrintCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validates and prints the configuration file",
+	Long: `Validates and prints the configuration file. The file is named .semver.yaml, .semver.yml or .semver.toml
+and is discovered from the path upward, through the repository root to the root of the file system, unless --config or
+SEMVER_CONFIG names it. The nearest file wins. Settings are taken from
+the flags, then the SEMVER_* environment variables, then the configuration file, then the defaults.`,
+}
+
+// configValidateOutput is the output of the config validate command.
+type configValidateOutput struct {
+	APIVersion string `json:"apiVersion"`
+	File       string `json:"file,omitempty"`
+	Valid      bool   `json:"valid"`
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the configuration file and the SEMVER_* environment variables",
+	Run: func(cmd *cobra.Command, _ []string) {
+		path, _ := cmd.Flags().GetString("path")
+		config, file, err := loadConfig(cmd, path)
+		if err == nil {
+			err = config.Validate()
+		}
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		printJSON(cmd, configValidateOutput{APIVersion: core.APIVersion, File: file, Valid: true})
+	},
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Prints the configuration file, or the effective settings with --effective",
+	Run: func(cmd *cobra.Command, _ []string) {
+		path, _ := cmd.Flags().GetString("path")
+		effective, _ := cmd.Flags().GetBool("effective")
+		config, file, err := loadConfig(cmd, path)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		if !effective {
+			if file == "" {
+				exitWithError(cmd, fmt.Errorf("%w: no configuration file found from %s", core.ErrInvalidConfig, path))
+			}
+			content, errRead := os.ReadFile(file)
+			if errRead != nil {
+				exitWithError(cmd, errRead)
+			}
+			fmt.Fprint(os.Stdout, string(content))
+
+			return
+		}
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(yamlIndent)
+		err = encoder.Encode(config)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+	},
+}
+
+// loadConfig returns the default settings overridden by the configuration file and then by the SEMVER_*
+// environment variables, along with the path of the configuration file. The file is named by --config or
+// SEMVER_CONFIG, or discovered from the path upward when the path is not empty.
+func loadConfig(cmd *cobra.Command, path string) (core.Config, string, error) {
+	config := core.DefaultConfig()
+
+	file, _ := cmd.Flags().GetString("config")
+	if file == "" {
+		file = os.Getenv(core.ConfigEnvironmentVariable("config"))
+	}
+
+	if file == "" && path != "" {
+		var err error
+
+		file, err = core.FindConfigFile(path)
+		if err != nil {
+			return config, "", err
+		}
+	}
+
+	if file != "" {
+		err := core.LoadConfigFile(file, &config)
+		if err != nil {
+			return config, file, err
+		}
+	}
+
+	return config, file, config.ApplyEnvironment(os.Getenv)
+}
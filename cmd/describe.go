@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/martoc/semver/core"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addCalculationFlags(describeCmd)
+	describeCmd.Flags().String("format", core.DescribeDev.String(),
+		"Format of the version: dev for 1.4.0-dev.7+g1a2b3c4, or git for 1.3.2-7-g1a2b3c4 like git describe")
+	describeCmd.Flags().String("dirty", "",
+		"Append a mark, dirty when no value is given, to the version when the working tree has uncommitted changes")
+	describeCmd.Flags().Lookup("dirty").NoOptDefVal = core.DefaultDirtyMark
+}
+
+var describeCmd = &cobra.Command{
+	Use:   "describe",
+	Short: "Prints a development version of HEAD based on the next version, without tagging",
+	Long: `Prints a development version of HEAD for snapshot builds, based on the next version calculated with
+the same settings as the calculate command and the number of commits since the previous version. A released HEAD
+is described by its version. No tag is created or pushed.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		name, _ := cmd.Flags().GetString("format")
+		dirtyMark, _ := cmd.Flags().GetString("dirty")
+		format, err := core.ParseDescribeFormat(name)
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		builder := core.NewDescribeCommandBuilder(newCalculateBuilder(cmd)).
+			SetFormat(format).
+			SetDirtyMark(dirtyMark)
+		ctx, cancel := withTimeout(cmd.Context(), timeout)
+		output, err := builder.BuildDescribeCommand().Describe(ctx)
+		cancel()
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		printJSON(cmd, output)
+	},
+}
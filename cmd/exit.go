@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/logger"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of the semver command, so that pipelines can branch on the type of failure.
+const (
+	ExitOK            = 0 // The command succeeded.
+	ExitError         = 1 // The command failed for any other reason.
+	ExitInvalidConfig = 2 // The flags, branch rules or version line are invalid.
+	ExitNotRepository = 3 // The path or URL is not a git repository.
+	ExitNoCommits     = 4 // The repository has no commit to calculate a version for.
+	ExitPushRejected  = 5 // A remote rejected the tags.
+	ExitTagExists     = 6 // The release tag already exists on another commit.
+	ExitNoRelease     = 7 // No commit since the previous version requires a release.
+)
+
+// Formats of the error written to the standard error by the --output flag.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var (
+	// errOutputFormat is returned when the --output flag is neither text nor json.
+	errOutputFormat = errors.New("invalid output format")
+	// errNoRelease is reported after the output when no commit since the previous version requires a release.
+	errNoRelease = errors.New("no release")
+)
+
+// exitError maps an error to an exit code and to the code reported in the error object.
+type exitError struct {
+	err      error
+	exitCode int
+	code     string
+}
+
+// exitErrors is checked in order, so that a push rejected because the tag exists reports tag_exists.
+var exitErrors = []exitError{
+	{err: core.ErrRepositoryNotFound, exitCode: ExitNotRepository, code: "not_a_repository"},
+	{err: git.ErrRepositoryNotExists, exitCode: ExitNotRepository, code: "not_a_repository"},
+	{err: transport.ErrRepositoryNotFound, exitCode: ExitNotRepository, code: "not_a_repository"},
+	{err: core.ErrNoCommits, exitCode: ExitNoCommits, code: "no_commits"},
+	{err: core.ErrInvalidBranchRule, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: core.ErrInvalidVersionLine, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: core.ErrInvalidConfig, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: core.ErrInvalidDescribeFormat, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: core.ErrInvalidDirtyMark, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: errOutputFormat, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: logger.ErrInvalidLevel, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: logger.ErrInvalidFormat, exitCode: ExitInvalidConfig, code: "invalid_config"},
+	{err: core.ErrTagConflict, exitCode: ExitTagExists, code: "tag_exists"},
+	{err: core.ErrPushRejected, exitCode: ExitPushRejected, code: "push_rejected"},
+	{err: errNoRelease, exitCode: ExitNoRelease, code: "no_release"},
+}
+
+// errorOutput is the error object written to the standard error with --output json.
+type errorOutput struct {
+	APIVersion string `json:"apiVersion"`
+	Code       string `json:"code"`
+	ExitCode   int    `json:"exit_code"`
+	Message    string `json:"message"`
+}
+
+// newErrorOutput classifies the error into its code and exit code.
+func newErrorOutput(err error) errorOutput {
+	for _, exitErr := range exitErrors {
+		if errors.Is(err, exitErr.err) {
+			return errorOutput{
+				APIVersion: core.APIVersion, Code: exitErr.code, ExitCode: exitErr.exitCode, Message: err.Error(),
+			}
+		}
+	}
+
+	return errorOutput{APIVersion: core.APIVersion, Code: "error", ExitCode: ExitError, Message: err.Error()}
+}
+
+// exitWithError writes the error to the standard error in the format of the --output flag
+// and exits with the exit code of the error.
+func exitWithError(cmd *cobra.Command, err error) {
+	output := newErrorOutput(err)
+
+	logger.GetInstance().WithField("exit_code", output.ExitCode).Debug(err)
+
+	format, _ := cmd.Flags().GetString("output")
+	if format == outputJSON {
+		jsonOutput, _ := json.Marshal(output)
+		fmt.Fprintln(os.Stderr, string(jsonOutput))
+	} else {
+		fmt.Fprintln(os.Stderr, "Error:", output.Message)
+	}
+
+	os.Exit(output.ExitCode)
+}
+
+// validateOutputFormat exits with ExitInvalidConfig when the --output flag is neither text nor json.
+func validateOutputFormat(cmd *cobra.Command) {
+	format, _ := cmd.Flags().GetString("output")
+	if format != outputText && format != outputJSON {
+		exitWithError(cmd, fmt.Errorf("%w: %q, expected %s or %s", errOutputFormat, format, outputText, outputJSON))
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorOutput_ShouldClassifyErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err      error
+		code     string
+		exitCode int
+	}{
+		{fmt.Errorf("%w: /tmp", core.ErrRepositoryNotFound), "not_a_repository", ExitNotRepository},
+		{git.ErrRepositoryNotExists, "not_a_repository", ExitNotRepository},
+		{fmt.Errorf("%w: reference not found", core.ErrNoCommits), "no_commits", ExitNoCommits},
+		{fmt.Errorf("%w: \"main:max-bump=huge\"", core.ErrInvalidBranchRule), "invalid_config", ExitInvalidConfig},
+		{fmt.Errorf("%w: origin refs/tags/v1.0.0: failed", core.ErrPushRejected), "push_rejected", ExitPushRejected},
+		{
+			fmt.Errorf("%w: origin refs/tags/v1.0.0: %w", core.ErrPushRejected, core.ErrTagConflict),
+			"tag_exists", ExitTagExists,
+		},
+		{fmt.Errorf("%w: no commit since 1.0.0 requires a release", errNoRelease), "no_release", ExitNoRelease},
+		{errors.New("unexpected"), "error", ExitError},
+	}
+
+	for _, test := range tests {
+		output := newErrorOutput(test.err)
+
+		assert.Equal(t, errorOutput{APIVersion: core.APIVersion, Code: test.code, ExitCode: test.exitCode, Message: test.err.Error()}, output)
+	}
+}
@@ -1,26 +1,68 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/martoc/semver/logger"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(calculateCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.PersistentFlags().String("config", "",
+		"Configuration file, discovered as .semver.yaml, .semver.yml or .semver.toml from the path upward when not set")
+	rootCmd.PersistentFlags().StringP("output", "o", outputText,
+		"Format of errors written to the standard error, text or json")
+	rootCmd.PersistentFlags().String("log-level", "info",
+		"Minimum level of log messages: trace, debug, info, warn, error, fatal or panic")
+	rootCmd.PersistentFlags().String("log-format", logger.FormatText, "Format of log messages, text or json")
+	rootCmd.PersistentFlags().String("log-file", "", "Append log messages to a file instead of the standard error")
 }
 
 var rootCmd = &cobra.Command{
 	Use:   "semver",
 	Short: "Generates and tag git repositories based on semantic versions based and conventional commits",
 	Long:  `Generates and tag git repositories based on semantic versions based and conventional commits`,
+
+	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+		validateOutputFormat(cmd)
+		configureLogger(cmd)
+	},
 }
 
+// configureLogger configures the shared logger from the log flags.
+func configureLogger(cmd *cobra.Command) {
+	level, _ := cmd.Flags().GetString("log-level")
+	format, _ := cmd.Flags().GetString("log-format")
+	file, _ := cmd.Flags().GetString("log-file")
+
+	err := logger.Configure(logger.Options{Level: level, Format: format, File: file})
+	if err != nil {
+		exitWithError(cmd, err)
+	}
+}
+
+// Execute runs the root command. The first SIGINT or SIGTERM cancels the context of the command, so that
+// it stops and rolls back the tags it created, and a second one terminates the process immediately.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		stop() // Restore the default behavior so that a second signal terminates the process
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(ExitInvalidConfig) // Cobra only fails on unknown commands and invalid flags
 	}
 }
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/martoc/semver/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema <name>",
+	Short: "Prints the JSON Schema of the configuration file or of the output of a command",
+	Long: `Prints the JSON Schema of the configuration file or of the output of a command. The schemas are
+versioned by the apiVersion field of the output: fields may be added within the same version, and
+removing or changing a field increments it. Available schemas: ` + strings.Join(schema.Names(), ", ") + ".",
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	ValidArgs: schema.Names(),
+	Run: func(cmd *cobra.Command, args []string) {
+		content, err := schema.Get(args[0])
+		if err != nil {
+			exitWithError(cmd, err)
+		}
+		fmt.Fprint(os.Stdout, string(content))
+	},
+}
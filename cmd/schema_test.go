@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaDocument is the part of a JSON Schema compared with the Go types.
+type schemaDocument struct {
+	Properties map[string]struct {
+		Const string `json:"const"`
+	} `json:"properties"`
+	Defs map[string]struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		OneOf      []struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"oneOf"`
+	} `json:"$defs"`
+}
+
+// fieldNames returns the names of the fields of the struct type encoded with the tag key.
+func fieldNames(value interface{}, key string) []string {
+	names := []string{}
+
+	structType := reflect.TypeOf(value)
+	for i := range structType.NumField() {
+		name, _, _ := strings.Cut(structType.Field(i).Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// loadSchema returns the named schema.
+func loadSchema(t *testing.T, name string) schemaDocument {
+	t.Helper()
+
+	content, err := schema.Get(name)
+	require.NoError(t, err)
+
+	var document schemaDocument
+
+	require.NoError(t, json.Unmarshal(content, &document))
+
+	return document
+}
+
+func TestSchema_ShouldDescribeOutputs(t *testing.T) {
+	t.Parallel()
+
+	outputs := map[string]interface{}{
+		"calculate":       core.CalculateOutput{},
+		"config-validate": configValidateOutput{},
+		"describe":        core.DescribeOutput{},
+		"error":           errorOutput{},
+		"version":         versionOutput{},
+	}
+
+	assert.Equal(t, "semver/"+schema.Version, core.APIVersion)
+
+	for name, output := range outputs {
+		document := loadSchema(t, name)
+
+		assert.ElementsMatch(t, fieldNames(output, "json"), slices.Collect(maps.Keys(document.Properties)), name)
+		assert.Equal(t, core.APIVersion, document.Properties["apiVersion"].Const, name)
+	}
+
+	document := loadSchema(t, "calculate")
+	nested := map[string]interface{}{
+		"tagChange":      core.TagChange{},
+		"pushResult":     core.PushResult{},
+		"rollbackResult": core.RollbackResult{},
+		"normalizedTag":  core.NormalizedTag{},
+	}
+
+	for name, value := range nested {
+		assert.ElementsMatch(t, fieldNames(value, "json"), slices.Collect(maps.Keys(document.Defs[name].Properties)), name)
+	}
+}
+
+func TestSchema_ShouldDescribeConfigFile(t *testing.T) {
+	t.Parallel()
+
+	document := loadSchema(t, "config")
+
+	assert.ElementsMatch(t, fieldNames(core.Config{}, "yaml"), slices.Collect(maps.Keys(document.Properties)))
+	assert.ElementsMatch(t, fieldNames(core.BranchRuleConfig{}, "yaml"),
+		slices.Collect(maps.Keys(document.Defs["branchRule"].OneOf[1].Properties)))
+	assert.ElementsMatch(t, fieldNames(core.StreamConfig{}, "yaml"), slices.Collect(maps.Keys(document.Defs["stream"].Properties)))
+	assert.ElementsMatch(t, fieldNames(core.FileConfig{}, "yaml"), slices.Collect(maps.Keys(document.Defs["file"].Properties)))
+	assert.ElementsMatch(t, fieldNames(core.ChangelogConfig{}, "yaml"), slices.Collect(maps.Keys(document.Defs["changelog"].Properties)))
+	assert.ElementsMatch(t, fieldNames(core.ChangelogSectionConfig{}, "yaml"),
+		slices.Collect(maps.Keys(document.Defs["changelogSection"].Properties)))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/martoc/semver/core"
 	"github.com/martoc/semver/logger"
 	"github.com/spf13/cobra"
 )
@@ -15,19 +16,24 @@ var (
 	CLIArch    string
 )
 
+// versionOutput is the output of the version command.
+type versionOutput struct {
+	APIVersion string `json:"apiVersion"`
+	Version    string `json:"version"`
+	Os         string `json:"os"`
+	Arch       string `json:"arch"`
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
 	Long:  `All software has versions`,
 	Run: func(_ *cobra.Command, _ []string) {
-		jsonData := struct {
-			Version string `json:"version"`
-			Os      string `json:"os"`
-			Arch    string `json:"arch"`
-		}{
-			Version: CLIVersion,
-			Os:      CLIOs,
-			Arch:    CLIArch,
+		jsonData := versionOutput{
+			APIVersion: core.APIVersion,
+			Version:    CLIVersion,
+			Os:         CLIOs,
+			Arch:       CLIArch,
 		}
 		jsonBytes, err := json.Marshal(jsonData)
 		if err != nil {
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultGitUser = "git"
+	tokenUsername  = "x-access-token"
+)
+
+// Credentials holds the secrets used to authenticate against remote repositories.
+type Credentials struct {
+	Username            string // HTTP basic authentication user name.
+	Password            string // HTTP basic authentication password.
+	Token               string // HTTP access token, sent as the basic authentication password.
+	SSHKeyFile          string // Path of the private key used for SSH remotes.
+	SSHKeyPassphrase    string // Passphrase of the SSH private key.
+	SSHAgent            bool   // Indicates if an SSH agent is available.
+	UseCredentialHelper bool   // Asks the configured git credential helper for HTTP credentials.
+}
+
+// CredentialsFromEnvironment reads the credentials from the environment.
+// It takes a lookup function such as os.Getenv and reads SEMVER_GIT_USERNAME, SEMVER_GIT_PASSWORD,
+// SEMVER_GIT_TOKEN, SEMVER_SSH_KEY_FILE, SEMVER_SSH_KEY_PASSPHRASE and SSH_AUTH_SOCK.
+// The git credential helper is used unless SEMVER_GIT_CREDENTIAL_HELPER is false.
+func CredentialsFromEnvironment(getenv func(string) string) Credentials {
+	useCredentialHelper, err := strconv.ParseBool(getenv("SEMVER_GIT_CREDENTIAL_HELPER"))
+	if err != nil {
+		useCredentialHelper = true
+	}
+
+	return Credentials{
+		Username:            getenv("SEMVER_GIT_USERNAME"),
+		Password:            getenv("SEMVER_GIT_PASSWORD"),
+		Token:               getenv("SEMVER_GIT_TOKEN"),
+		SSHKeyFile:          getenv("SEMVER_SSH_KEY_FILE"),
+		SSHKeyPassphrase:    getenv("SEMVER_SSH_KEY_PASSPHRASE"),
+		SSHAgent:            getenv("SSH_AUTH_SOCK") != "",
+		UseCredentialHelper: useCredentialHelper,
+	}
+}
+
+// AuthMethod returns the go-git authentication method for the remote URL.
+// HTTP remotes use the token, then the user name and password, then the git credential helper
+// run from repositoryPath, whose failures are logged at debug level to log, or to the shared logger
+// when nil. SSH remotes use the key file, then the SSH agent. Local remotes and remotes without
+// credentials return a nil AuthMethod.
+func (c Credentials) AuthMethod(log logrus.FieldLogger, repositoryPath, remoteURL string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch endpoint.Protocol {
+	case "http", "https":
+		return c.httpAuth(log, repositoryPath, endpoint), nil
+	case "ssh":
+		return c.sshAuth(endpoint)
+	default:
+		return nil, nil //nolint:nilnil
+	}
+}
+
+// httpAuth returns the basic authentication for an HTTP endpoint, or nil when no credentials are known.
+func (c Credentials) httpAuth(log logrus.FieldLogger, repositoryPath string, endpoint *transport.Endpoint) transport.AuthMethod {
+	switch {
+	case c.Token != "":
+		username := c.Username
+		if username == "" {
+			username = tokenUsername
+		}
+
+		return &http.BasicAuth{Username: username, Password: c.Token}
+	case c.Username != "" || c.Password != "":
+		return &http.BasicAuth{Username: c.Username, Password: c.Password}
+	case c.UseCredentialHelper:
+		username, password, err := GitCredentialFill(repositoryPath, endpoint)
+		if err != nil {
+			orDefaultLogger(log).Debugln("git credential fill:", err)
+
+			return nil
+		}
+
+		if username != "" || password != "" {
+			return &http.BasicAuth{Username: username, Password: password}
+		}
+	}
+
+	return nil
+}
+
+// sshAuth returns the public key or agent authentication for an SSH endpoint,
+// or nil to let go-git use its defaults.
+func (c Credentials) sshAuth(endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	user := endpoint.User
+	if user == "" {
+		user = defaultGitUser
+	}
+
+	if c.SSHKeyFile != "" {
+		return ssh.NewPublicKeysFromFile(user, c.SSHKeyFile, c.SSHKeyPassphrase)
+	}
+
+	if c.SSHAgent {
+		return ssh.NewSSHAgentAuth(user)
+	}
+
+	return nil, nil //nolint:nilnil
+}
+
+// GitCredentialFill asks the credential helpers configured for the repository at repositoryPath
+// for the user name and password of the endpoint, using `git credential fill`. When repositoryPath
+// is not a directory, such as the URL of a remote-only repository, only the global helpers are asked.
+// Terminal prompts are disabled so that it never blocks waiting for input.
+func GitCredentialFill(repositoryPath string, endpoint *transport.Endpoint) (string, string, error) {
+	var input bytes.Buffer
+
+	input.WriteString("protocol=" + endpoint.Protocol + "\n")
+	input.WriteString("host=" + endpoint.Host)
+
+	if endpoint.Port != 0 {
+		input.WriteString(":" + strconv.Itoa(endpoint.Port))
+	}
+
+	input.WriteString("\npath=" + strings.TrimPrefix(endpoint.Path, "/") + "\n\n")
+
+	cmd := exec.Command("git", "credential", "fill")
+	if info, err := os.Stat(repositoryPath); err == nil && info.IsDir() {
+		cmd.Dir = repositoryPath
+	}
+
+	cmd.Stdin = &input
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=", "SSH_ASKPASS=")
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", err
+	}
+
+	var username, password string
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), "=")
+
+		switch key {
+		case "username":
+			username = value
+		case "password":
+			password = value
+		}
+	}
+
+	return username, password, scanner.Err()
+}
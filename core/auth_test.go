@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+func TestCredentialsFromEnvironment(t *testing.T) {
+	t.Parallel()
+
+	environment := map[string]string{
+		"SEMVER_GIT_USERNAME":          "alice",
+		"SEMVER_GIT_PASSWORD":          "password",
+		"SEMVER_GIT_TOKEN":             "token",
+		"SEMVER_SSH_KEY_FILE":          "/home/alice/.ssh/id_ed25519",
+		"SEMVER_SSH_KEY_PASSPHRASE":    "passphrase",
+		"SSH_AUTH_SOCK":                "/tmp/agent.sock",
+		"SEMVER_GIT_CREDENTIAL_HELPER": "false",
+	}
+
+	credentials := core.CredentialsFromEnvironment(func(name string) string { return environment[name] })
+
+	assert.Equal(t, core.Credentials{
+		Username:         "alice",
+		Password:         "password",
+		Token:            "token",
+		SSHKeyFile:       "/home/alice/.ssh/id_ed25519",
+		SSHKeyPassphrase: "passphrase",
+		SSHAgent:         true,
+	}, credentials)
+	assert.True(t, core.CredentialsFromEnvironment(func(string) string { return "" }).UseCredentialHelper)
+}
+
+func TestCredentials_AuthMethodShouldUseTokenForHTTP(t *testing.T) {
+	t.Parallel()
+
+	auth, err := core.Credentials{Token: "token"}.AuthMethod(nil, ".", "https://github.com/martoc/semver.git")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &http.BasicAuth{Username: "x-access-token", Password: "token"}, auth)
+}
+
+func TestCredentials_AuthMethodShouldUseBasicAuthForHTTP(t *testing.T) {
+	t.Parallel()
+
+	auth, err := core.Credentials{Username: "alice", Password: "password"}.AuthMethod(nil, ".", "https://example.com/repo.git")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &http.BasicAuth{Username: "alice", Password: "password"}, auth)
+}
+
+func TestCredentials_AuthMethodShouldUseConfiguredCredentialHelper(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	config, err := repo.Config()
+	require.NoError(t, err)
+	config.Raw.Section("credential").SetOption("helper", "!f() { echo username=helper-user; echo password=helper-secret; }; f")
+	require.NoError(t, repo.SetConfig(config))
+
+	auth, err := core.Credentials{UseCredentialHelper: true}.AuthMethod(nil, path, "https://example.com/repo.git")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &http.BasicAuth{Username: "helper-user", Password: "helper-secret"}, auth)
+}
+
+func TestCredentials_AuthMethodShouldLogCredentialHelperFailuresAtDebugLevel(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	config, err := repo.Config()
+	require.NoError(t, err)
+	config.Raw.Section("credential").SetOption("helper", "!f() { exit 1; }; f")
+	require.NoError(t, repo.SetConfig(config))
+
+	log, hook := logtest.NewNullLogger()
+	log.SetLevel(logrus.DebugLevel)
+
+	auth, err := core.Credentials{UseCredentialHelper: true}.AuthMethod(log, path, "https://example.com/repo.git")
+
+	assert.NoError(t, err)
+	assert.Nil(t, auth)
+	require.Len(t, hook.AllEntries(), 1)
+	assert.Equal(t, logrus.DebugLevel, hook.LastEntry().Level)
+}
+
+func TestGitCredentialFill_ShouldSendEndpointToHelper(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	config, err := repo.Config()
+	require.NoError(t, err)
+	config.Raw.Section("credential").SetOption("helper",
+		`!f() { while read line; do case "$line" in host=*) echo "username=${line#host=}";; path=*) echo "password=${line#path=}";; esac; done; }; f`)
+	config.Raw.Section("credential").SetOption("useHttpPath", "true")
+	require.NoError(t, repo.SetConfig(config))
+
+	endpoint, err := transport.NewEndpoint("https://example.com:8443/org/repo.git")
+	require.NoError(t, err)
+
+	username, password, err := core.GitCredentialFill(path, endpoint)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com:8443", username)
+	assert.Equal(t, "org/repo.git", password)
+}
+
+func TestCredentials_AuthMethodShouldUseKeyFileForSSH(t *testing.T) {
+	t.Parallel()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	block, err := cryptossh.MarshalPrivateKey(privateKey, "semver")
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(block), 0o600))
+
+	auth, err := core.Credentials{SSHKeyFile: keyFile}.AuthMethod(nil, ".", "git@github.com:martoc/semver.git")
+
+	require.NoError(t, err)
+	require.IsType(t, &ssh.PublicKeys{}, auth)
+	assert.Equal(t, "git", auth.(*ssh.PublicKeys).User) //nolint:forcetypeassert
+}
+
+func TestCredentials_AuthMethodShouldFailForMissingKeyFile(t *testing.T) {
+	t.Parallel()
+
+	keyFile := filepath.Join(t.TempDir(), "missing")
+
+	_, err := core.Credentials{SSHKeyFile: keyFile}.AuthMethod(nil, ".", "ssh://deploy@example.com/repo.git")
+
+	assert.Error(t, err)
+}
+
+func TestCredentials_AuthMethodShouldNotAuthenticateLocalRemotes(t *testing.T) {
+	t.Parallel()
+
+	credentials := core.Credentials{Token: "token", SSHKeyFile: "/missing"}
+
+	for _, remoteURL := range []string{"/srv/git/repo.git", "file:///srv/git/repo.git"} {
+		auth, err := credentials.AuthMethod(nil, ".", remoteURL)
+
+		assert.NoError(t, err, remoteURL)
+		assert.Nil(t, auth, remoteURL)
+	}
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+)
+
+// BaseTagPolicy selects the release tag the next version is calculated from.
+type BaseTagPolicy int
+
+const (
+	// BaseTagGreatestReachable selects the greatest tag on a commit reachable from HEAD.
+	BaseTagGreatestReachable BaseTagPolicy = iota
+	// BaseTagNearest selects the tag on the reachable commit nearest to HEAD, counting parent links.
+	// The greatest tag is selected when several commits are at the same distance.
+	BaseTagNearest
+	// BaseTagGreatest selects the greatest tag in the repository, even when its commit is not reachable
+	// from HEAD, such as a release branch that was never merged back. The tags on commits that are not walked
+	// from HEAD are reported.
+	BaseTagGreatest
+)
+
+// ErrInvalidBaseTagPolicy is returned when a base tag policy name cannot be parsed.
+var ErrInvalidBaseTagPolicy = errors.New("invalid base tag policy")
+
+// baseTagPolicies lists the base tag policies in the order of their values.
+var baseTagPolicies = []BaseTagPolicy{BaseTagGreatestReachable, BaseTagNearest, BaseTagGreatest}
+
+// String returns the name of the base tag policy.
+func (p BaseTagPolicy) String() string {
+	switch p {
+	case BaseTagGreatestReachable:
+		return "greatest-reachable"
+	case BaseTagNearest:
+		return "nearest"
+	case BaseTagGreatest:
+		return "greatest"
+	default:
+		return fmt.Sprintf("BaseTagPolicy(%d)", int(p))
+	}
+}
+
+// ParseBaseTagPolicy parses greatest-reachable, nearest or greatest. An empty name is greatest-reachable.
+func ParseBaseTagPolicy(name string) (BaseTagPolicy, error) {
+	if name == "" {
+		return BaseTagGreatestReachable, nil
+	}
+
+	for _, policy := range baseTagPolicies {
+		if policy.String() == name {
+			return policy, nil
+		}
+	}
+
+	return BaseTagGreatestReachable, fmt.Errorf("%w: %q, expected greatest-reachable, nearest or greatest",
+		ErrInvalidBaseTagPolicy, name)
+}
+
+// baseVersion returns the version the next version is calculated from, selected by the base tag policy
+// among the stable tags inside the version line, and the reachable tag bounding the released commits:
+// the nearest tag for the nearest policy, or else the greatest tag reachable from HEAD. When the policy
+// considers every tag of the repository, the tags that are not on a commit walked from HEAD are reported
+// in the output.
+func (c *CalculateCommandImpl) baseVersion(output *CalculateOutput, commitLogs []*CommitLog, line VersionLine,
+) (semver.Version, semver.Version, error) {
+	reachable, _ := semver.Make("0.0.0")
+	walked := map[string]bool{}
+
+	for _, commit := range commitLogs {
+		tags := line.Filter(channelTags(commit.Tags, nil))
+		for _, tag := range tags {
+			walked[tag.String()] = true
+		}
+
+		reachable = c.GetGreatestTag(reachable, tags)
+	}
+
+	switch c.BaseTag {
+	case BaseTagNearest:
+		nearest, _ := semver.Make("0.0.0")
+		nearest = c.GetGreatestTag(nearest, nearestTags(commitLogs, line))
+
+		return nearest, nearest, nil
+	case BaseTagGreatest:
+		tags, err := c.Scm.GetTags()
+		if err != nil {
+			return reachable, reachable, err
+		}
+
+		unreachable := []semver.Version{}
+
+		for _, tag := range line.Filter(channelTags(tags, nil)) {
+			if !walked[tag.String()] {
+				walked[tag.String()] = true // Reports a version tagged twice once
+				unreachable = append(unreachable, *tag)
+			}
+		}
+
+		semver.Sort(unreachable)
+
+		greatest := reachable
+
+		for i := range unreachable {
+			output.UnreachableTags = append(output.UnreachableTags, c.Stream.TagName(unreachable[i].String()))
+			greatest = c.GetGreatestTag(greatest, []*semver.Version{&unreachable[i]})
+		}
+
+		if greatest.GT(reachable) {
+			c.log().Warnln("Calculating from", c.Stream.TagName(greatest.String()), "which is not reachable from HEAD")
+		}
+
+		return greatest, reachable, nil
+	case BaseTagGreatestReachable:
+		return reachable, reachable, nil
+	default:
+		return reachable, reachable, nil
+	}
+}
+
+// nearestTags returns the stable tags inside the version line of the tagged commits nearest to HEAD,
+// walking the parents of the commits breadth first.
+func nearestTags(commitLogs []*CommitLog, line VersionLine) []*semver.Version {
+	if len(commitLogs) == 0 {
+		return nil
+	}
+
+	byHash := make(map[string]*CommitLog, len(commitLogs))
+	for _, commit := range commitLogs {
+		byHash[commit.Hash] = commit
+	}
+
+	visited := map[string]bool{commitLogs[0].Hash: true}
+	distance := []*CommitLog{commitLogs[0]}
+
+	for len(distance) > 0 {
+		tags := []*semver.Version{}
+		parents := []*CommitLog{}
+
+		for _, commit := range distance {
+			tags = append(tags, line.Filter(channelTags(commit.Tags, nil))...)
+
+			for _, hash := range commit.Parents {
+				parent, walked := byHash[hash]
+				if walked && !visited[hash] {
+					visited[hash] = true
+					parents = append(parents, parent)
+				}
+			}
+		}
+
+		if len(tags) > 0 {
+			return tags
+		}
+
+		distance = parents
+	}
+
+	return nil
+}
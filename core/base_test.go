@@ -0,0 +1,195 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBaseTagPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		policy core.BaseTagPolicy
+	}{
+		{"", core.BaseTagGreatestReachable},
+		{"greatest-reachable", core.BaseTagGreatestReachable},
+		{"nearest", core.BaseTagNearest},
+		{"greatest", core.BaseTagGreatest},
+	}
+
+	for _, test := range tests {
+		policy, err := core.ParseBaseTagPolicy(test.name)
+
+		require.NoError(t, err)
+		assert.Equal(t, test.policy, policy)
+	}
+
+	_, err := core.ParseBaseTagPolicy("latest")
+
+	assert.ErrorIs(t, err, core.ErrInvalidBaseTagPolicy)
+}
+
+// initUnmergedReleaseBranch creates a repository where v1.1.0 was tagged on a release branch that was
+// never merged back into master, which has a fix since v1.0.0.
+func initUnmergedReleaseBranch(t *testing.T) string {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	release := gittest.CommitFile(t, repo, "feat: release only feature")
+	_, err = repo.CreateTag("v1.1.0", release, nil)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}))
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	return path
+}
+
+func TestCalculateCommandImpl_ShouldIgnoreUnreachableTagsByDefault(t *testing.T) {
+	t.Parallel()
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(initUnmergedReleaseBranch(t)).
+		SetDisableTagging(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.PreviousVersion)
+	assert.Equal(t, "1.0.1", result.NextVersion)
+	assert.Empty(t, result.UnreachableTags)
+}
+
+func TestCalculateCommandImpl_ShouldCalculateFromGreatestTagAndReportUnreachableTags(t *testing.T) {
+	t.Parallel()
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(initUnmergedReleaseBranch(t)).
+		SetBaseTag(core.BaseTagGreatest).
+		SetDisableTagging(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.PreviousVersion)
+	assert.Equal(t, "1.1.1", result.NextVersion)
+	assert.Equal(t, []string{"v1.1.0"}, result.UnreachableTags)
+	assert.Len(t, result.Commits, 1)
+}
+
+func TestCalculateCommandImpl_ShouldCalculateFromNearestTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy          core.BaseTagPolicy
+		previousVersion string
+		nextVersion     string
+	}{
+		{core.BaseTagGreatestReachable, "2.0.0", "2.1.0"},
+		{core.BaseTagNearest, "1.5.0", "1.5.1"},
+	}
+
+	for _, test := range tests {
+		path, repo := gittest.InitRepository(t)
+
+		major := gittest.CommitFile(t, repo, "feat!: new API")
+		_, err := repo.CreateTag("v2.0.0", major, nil)
+		require.NoError(t, err)
+
+		backport := gittest.CommitFile(t, repo, "feat: backported feature")
+		_, err = repo.CreateTag("v1.5.0", backport, nil)
+		require.NoError(t, err)
+
+		gittest.CommitFile(t, repo, "fix: a fix")
+
+		result, err := core.NewCalculateCommandBuilder().
+			SetPath(path).
+			SetBaseTag(test.policy).
+			SetDisableTagging(true).
+			BuildCalculateCommand().
+			Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, test.previousVersion, result.PreviousVersion, test.policy)
+		assert.Equal(t, test.nextVersion, result.NextVersion, test.policy)
+	}
+}
+
+func TestCalculateCommandImpl_ShouldReportUnreachableTagsLowerThanPreviousVersion(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	hotfix := gittest.CommitFile(t, repo, "fix: hotfix only fix")
+	_, err = repo.CreateTag("v1.0.1", hotfix, nil)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}))
+
+	feature := gittest.CommitFile(t, repo, "feat: a feature")
+	_, err = repo.CreateTag("v1.1.0", feature, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetBaseTag(core.BaseTagGreatest).
+		SetDisableTagging(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.PreviousVersion)
+	assert.Equal(t, "1.1.1", result.NextVersion)
+	assert.Equal(t, []string{"v1.0.1"}, result.UnreachableTags)
+}
+
+func TestCalculateCommandImpl_ShouldReportTagsOutsideWalkedHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		history         core.HistoryMode
+		unreachableTags []string
+	}{
+		{core.HistoryAll, nil},
+		{core.HistoryFirstParent, []string{"v9.0.0"}},
+	}
+
+	for _, test := range tests {
+		branch := initMergedBranch(t)
+
+		result, err := core.NewCalculateCommandBuilder().
+			SetPath(branch.path).
+			SetHistory(test.history).
+			SetBaseTag(core.BaseTagGreatest).
+			SetDisableTagging(true).
+			BuildCalculateCommand().
+			Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "9.0.0", result.PreviousVersion, test.history)
+		assert.Equal(t, test.unreachableTags, result.UnreachableTags, test.history)
+	}
+}
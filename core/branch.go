@@ -0,0 +1,213 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	slugPlaceholder     = "{slug}"
+	slugSample          = "x" // Stands for the slug when the prerelease of a rule is validated.
+	branchRuleSeparator = ":"
+	branchRuleKeyValues = 2
+)
+
+var (
+	// ErrInvalidBranchRule is returned when a branch rule cannot be parsed.
+	ErrInvalidBranchRule = errors.New("invalid branch rule")
+
+	errUnknownBranchRuleOption = errors.New("unknown option")
+)
+
+// branchEnvironmentVariables lists the CI environment variables holding the branch name,
+// in the order they are consulted when HEAD is detached.
+var branchEnvironmentVariables = []string{
+	"SEMVER_BRANCH",
+	"GITHUB_HEAD_REF",     // GitHub Actions pull requests
+	"GITHUB_REF_NAME",     // GitHub Actions
+	"CI_COMMIT_BRANCH",    // GitLab CI
+	"BUILD_SOURCEBRANCH",  // Azure Pipelines
+	"BITBUCKET_BRANCH",    // Bitbucket Pipelines
+	"CIRCLE_BRANCH",       // CircleCI
+	"BRANCH_NAME",         // Jenkins
+	"BUILDKITE_BRANCH",    // Buildkite
+	"TRAVIS_BRANCH",       // Travis CI
+	"DRONE_SOURCE_BRANCH", // Drone
+}
+
+var slugInvalidCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// BranchRule describes how versions are released from branches matching Pattern.
+type BranchRule struct {
+	Pattern        string                   // Glob matched against the branch name, * does not match a /.
+	Prerelease     string                   // Prerelease channel, for example beta or feat-{slug}. Empty for stable releases.
+	MaxBump        SemanticVersionComponent // The largest version component a release may increment.
+	Line           VersionLine              // Versions releases from matching branches must stay within.
+	DisableTagging bool                     // Disables tagging for matching branches.
+	DisablePush    bool                     // Disables pushing tags for matching branches.
+}
+
+// ParseBranchRule parses a branch rule of the form pattern[:key=value,...].
+// Supported keys are prerelease, max-bump (major, minor or patch), line (for example 1.x),
+// tag and push (true or false).
+// For example "feature/*:prerelease=feat-{slug},push=false". The prerelease must be made of
+// identifiers valid in a semantic version.
+func ParseBranchRule(value string) (BranchRule, error) {
+	pattern, options, _ := strings.Cut(value, branchRuleSeparator)
+
+	rule := BranchRule{Pattern: strings.TrimSpace(pattern)}
+	if rule.Pattern == "" {
+		return rule, fmt.Errorf("%w: %q has no branch pattern", ErrInvalidBranchRule, value)
+	}
+
+	if _, err := path.Match(rule.Pattern, ""); err != nil {
+		return rule, fmt.Errorf("%w: %q: %w", ErrInvalidBranchRule, value, err)
+	}
+
+	for _, option := range strings.Split(options, ",") {
+		if strings.TrimSpace(option) == "" {
+			continue
+		}
+
+		keyValue := strings.SplitN(option, "=", branchRuleKeyValues)
+		if len(keyValue) != branchRuleKeyValues {
+			return rule, fmt.Errorf("%w: %q: option %q is not key=value", ErrInvalidBranchRule, value, option)
+		}
+
+		if err := rule.set(strings.TrimSpace(keyValue[0]), strings.TrimSpace(keyValue[1])); err != nil {
+			return rule, fmt.Errorf("%w: %q: %w", ErrInvalidBranchRule, value, err)
+		}
+	}
+
+	return rule, nil
+}
+
+// set assigns a single option of a branch rule.
+func (r *BranchRule) set(key, value string) error {
+	var err error
+
+	switch key {
+	case "prerelease":
+		r.Prerelease = value
+		_, err = prereleaseIdentifiers(strings.ReplaceAll(value, slugPlaceholder, slugSample))
+	case "max-bump":
+		r.MaxBump, err = ParseSemanticVersionComponent(value)
+		if err == nil && r.MaxBump == NONE {
+			err = fmt.Errorf("%w: max-bump cannot be %q, use tag=false instead", ErrInvalidVersionComponent, value)
+		}
+	case "line":
+		r.Line, err = ParseVersionLine(value)
+	case "tag":
+		var tag bool
+		tag, err = strconv.ParseBool(value)
+		r.DisableTagging = !tag
+	case "push":
+		var push bool
+		push, err = strconv.ParseBool(value)
+		r.DisablePush = !push
+	default:
+		err = fmt.Errorf("%w %q", errUnknownBranchRuleOption, key)
+	}
+
+	return err
+}
+
+// Matches reports whether the branch name matches the rule pattern. The pattern is matched as by
+// path.Match, so a * does not match a /: feature/* matches feature/login but not feature/team/login,
+// which needs feature/*/*.
+func (r *BranchRule) Matches(branch string) bool {
+	matched, err := path.Match(r.Pattern, branch)
+
+	return err == nil && matched
+}
+
+// Channel returns the prerelease identifiers for the branch, replacing {slug} with the slug
+// of the branch name without the literal prefix of the pattern, so feature/login matched
+// by feature/* has the slug login. A numeric identifier loses its leading zeros, so the slug of
+// issue/0123 matched by issue/* is 123. It returns nil for stable releases, and ErrInvalidBranchRule
+// when an identifier of the channel is not valid in a semantic version.
+func (r *BranchRule) Channel(branch string) ([]semver.PRVersion, error) {
+	if r.Prerelease == "" {
+		return nil, nil
+	}
+
+	slug := Slug(strings.TrimPrefix(branch, r.literalPrefix()))
+	if slug == "" {
+		slug = Slug(branch)
+	}
+
+	versions, err := prereleaseIdentifiers(strings.ReplaceAll(r.Prerelease, slugPlaceholder, slug))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q for branch %q: %w", ErrInvalidBranchRule, r.Pattern, branch, err)
+	}
+
+	return versions, nil
+}
+
+// prereleaseIdentifiers parses the dot separated identifiers of a prerelease, trimming the leading
+// zeros of numeric identifiers.
+func prereleaseIdentifiers(prerelease string) ([]semver.PRVersion, error) {
+	versions := []semver.PRVersion{}
+
+	for _, identifier := range strings.Split(prerelease, ".") {
+		if identifier != "" && strings.Trim(identifier, "0123456789") == "" {
+			identifier = strings.TrimLeft(identifier, "0")
+			if identifier == "" {
+				identifier = "0"
+			}
+		}
+
+		version, err := semver.NewPRVersion(identifier)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+// literalPrefix returns the part of the pattern before its first wildcard.
+func (r *BranchRule) literalPrefix() string {
+	if index := strings.IndexAny(r.Pattern, "*?[\\"); index >= 0 {
+		return r.Pattern[:index]
+	}
+
+	return r.Pattern
+}
+
+// MatchBranchRule returns the first rule matching the branch name, or nil when none matches.
+func MatchBranchRule(rules []BranchRule, branch string) *BranchRule {
+	for i := range rules {
+		if rules[i].Matches(branch) {
+			return &rules[i]
+		}
+	}
+
+	return nil
+}
+
+// BranchFromEnvironment returns the branch name exposed by well-known CI systems.
+// It takes a lookup function such as os.Getenv and returns an empty string when no branch is found.
+func BranchFromEnvironment(getenv func(string) string) string {
+	for _, name := range branchEnvironmentVariables {
+		if branch := getenv(name); branch != "" {
+			return strings.TrimPrefix(branch, "refs/heads/")
+		}
+	}
+
+	return ""
+}
+
+// Slug converts a branch name into a string usable as a prerelease identifier.
+// For example feature/Add_Login becomes feature-add-login.
+func Slug(branch string) string {
+	return strings.Trim(slugInvalidCharacters.ReplaceAllString(strings.ToLower(branch), "-"), "-")
+}
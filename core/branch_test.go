@@ -0,0 +1,161 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBranchRule_ShouldParseAllOptions(t *testing.T) {
+	t.Parallel()
+
+	rule, err := core.ParseBranchRule("feature/*:prerelease=feat-{slug}, max-bump=minor, tag=true, push=false")
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.BranchRule{
+		Pattern:     "feature/*",
+		Prerelease:  "feat-{slug}",
+		MaxBump:     core.MINOR,
+		DisablePush: true,
+	}, rule)
+}
+
+func TestParseBranchRule_ShouldParsePatternOnly(t *testing.T) {
+	t.Parallel()
+
+	rule, err := core.ParseBranchRule("main")
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.BranchRule{Pattern: "main", MaxBump: core.MAJOR}, rule)
+}
+
+func TestParseBranchRule_ShouldRejectInvalidRules(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{
+		"",
+		":prerelease=beta",
+		"[main",
+		"main:prerelease",
+		"main:max-bump=huge",
+		"main:max-bump=none",
+		"main:push=maybe",
+		"main:color=blue",
+		"main:prerelease=beta_1",
+		"main:prerelease=beta..1",
+	} {
+		_, err := core.ParseBranchRule(value)
+
+		assert.ErrorIs(t, err, core.ErrInvalidBranchRule, value)
+	}
+}
+
+func TestMatchBranchRule_ShouldReturnFirstMatchingRule(t *testing.T) {
+	t.Parallel()
+
+	rules := []core.BranchRule{
+		{Pattern: "main"},
+		{Pattern: "feature/*", Prerelease: "feat-{slug}"},
+		{Pattern: "*", Prerelease: "dev"},
+	}
+
+	assert.Equal(t, &rules[0], core.MatchBranchRule(rules, "main"))
+	assert.Equal(t, &rules[1], core.MatchBranchRule(rules, "feature/login"))
+	assert.Equal(t, &rules[2], core.MatchBranchRule(rules, "develop"))
+	assert.Nil(t, core.MatchBranchRule(rules, "feature/login/form"))
+	assert.Nil(t, core.MatchBranchRule(nil, "main"))
+}
+
+func TestBranchRule_ChannelShouldReplaceSlug(t *testing.T) {
+	t.Parallel()
+
+	feature := core.BranchRule{Pattern: "feature/*", Prerelease: "feat-{slug}"}
+	develop := core.BranchRule{Pattern: "develop", Prerelease: "beta"}
+	main := core.BranchRule{Pattern: "main"}
+
+	channel, err := feature.Channel("feature/Add_Login")
+	require.NoError(t, err)
+	assert.Equal(t, []semver.PRVersion{{VersionStr: "feat-add-login"}}, channel)
+
+	channel, err = develop.Channel("develop")
+	require.NoError(t, err)
+	assert.Equal(t, []semver.PRVersion{{VersionStr: "beta"}}, channel)
+
+	channel, err = main.Channel("main")
+	require.NoError(t, err)
+	assert.Nil(t, channel)
+}
+
+func TestBranchRule_ChannelShouldTrimLeadingZerosOfNumericSlug(t *testing.T) {
+	t.Parallel()
+
+	issue := core.BranchRule{Pattern: "issue/*", Prerelease: "{slug}"}
+
+	channel, err := issue.Channel("issue/0123")
+	require.NoError(t, err)
+	assert.Equal(t, []semver.PRVersion{{VersionNum: 123, IsNum: true}}, channel)
+
+	channel, err = issue.Channel("issue/000")
+	require.NoError(t, err)
+	assert.Equal(t, []semver.PRVersion{{VersionNum: 0, IsNum: true}}, channel)
+}
+
+func TestBranchRule_ChannelShouldRejectInvalidIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	for branch, rule := range map[string]core.BranchRule{
+		"develop": {Pattern: "develop", Prerelease: "beta_1"},
+		"main":    {Pattern: "main", Prerelease: "rc..1"},
+		"___":     {Pattern: "*", Prerelease: "{slug}"},
+	} {
+		channel, err := rule.Channel(branch)
+
+		assert.ErrorIs(t, err, core.ErrInvalidBranchRule, branch)
+		assert.Nil(t, channel)
+	}
+}
+
+func TestBranchFromEnvironment_ShouldUseFirstDefinedVariable(t *testing.T) {
+	t.Parallel()
+
+	environment := map[string]string{
+		"GITHUB_REF_NAME":    "main",
+		"BUILD_SOURCEBRANCH": "refs/heads/develop",
+	}
+
+	getenv := func(name string) string {
+		return environment[name]
+	}
+
+	assert.Equal(t, "main", core.BranchFromEnvironment(getenv))
+
+	delete(environment, "GITHUB_REF_NAME")
+	assert.Equal(t, "develop", core.BranchFromEnvironment(getenv))
+
+	delete(environment, "BUILD_SOURCEBRANCH")
+	assert.Empty(t, core.BranchFromEnvironment(getenv))
+}
+
+func TestSlug(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "feature-add-login", core.Slug("feature/Add_Login"))
+	assert.Equal(t, "jira-123-fix", core.Slug("--JIRA-123 fix--"))
+}
+
+func TestParseBranchRule_ShouldParseVersionLine(t *testing.T) {
+	t.Parallel()
+
+	rule, err := core.ParseBranchRule("release/1.x:line=1.x")
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.VersionLine("1.x"), rule.Line)
+
+	_, err = core.ParseBranchRule("release/1.x:line=one")
+
+	assert.ErrorIs(t, err, core.ErrInvalidBranchRule)
+	assert.ErrorIs(t, err, core.ErrInvalidVersionLine)
+}
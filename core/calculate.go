@@ -2,30 +2,78 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/blang/semver/v4"
-	"github.com/martoc/semver/logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/sirupsen/logrus"
 )
 
 const (
-	versionPrefix = "v"
+	versionPrefix      = "v"
+	maxReleaseAttempts = 3
 )
 
 // CalculateOutput represents the output of the version calculation.
 type CalculateOutput struct {
-	NextVersion          string `json:"next_version"`
-	FloatingVersionMajor string `json:"floating_version_major"`
-	FloatingVersionMinor string `json:"floating_version_minor"`
+	APIVersion           string           `json:"apiVersion"`
+	PreviousVersion      string           `json:"previous_version,omitempty"`
+	NextVersion          string           `json:"next_version"`
+	Stream               string           `json:"stream,omitempty"`
+	Bump                 string           `json:"bump,omitempty"`
+	Released             bool             `json:"released"`
+	FloatingVersionMajor string           `json:"floating_version_major"`
+	FloatingVersionMinor string           `json:"floating_version_minor"`
+	FloatingTagsMoved    []string         `json:"floating_tags_moved,omitempty"`
+	FloatingTagsSkipped  []string         `json:"floating_tags_skipped,omitempty"`
+	Branch               string           `json:"branch,omitempty"`
+	TagsCreated          []string         `json:"tags_created,omitempty"`
+	Fetched              []TagChange      `json:"fetched,omitempty"`
+	Pushed               []PushResult     `json:"pushed,omitempty"`
+	RolledBack           []RollbackResult `json:"rolled_back,omitempty"`
+	UnreachableTags      []string         `json:"unreachable_tags,omitempty"` // Tags on commits not walked from HEAD.
+	NormalizedTags       []NormalizedTag  `json:"normalized_tags,omitempty"`  // Legacy tags parsed as versions.
+	IgnoredTags          []string         `json:"ignored_tags,omitempty"`     // Tags that are not versions.
+	FilesUpdated         []string         `json:"files_updated,omitempty"`    // Files the version or the changelog was written to.
+	Commits              []*CommitLog     `json:"-"`                          // The commits released by the next version, newest first.
 }
 
 // CalculateCommandBuilder is a builder for creating CalculateCommand instances.
 type CalculateCommandBuilder struct {
 	Scm             Scm
 	Path            string
+	RepositoryURL   string
 	AddFloatingTags bool
+	AddLatestTag    bool
+	AddStableTag    bool
 	Push            bool
 	DisableTagging  bool
+	Deepen          bool
+	History         HistoryMode
+	BaseTag         BaseTagPolicy
+	TagParser       TagParser
+	TagFilter       TagFilter
+	Stream          Stream
+	Fetch           bool
+	Prune           bool
+	RequireClean    bool
+	Credentials     *Credentials
+	Branch          string
+	BranchRules     []BranchRule
+	Line            VersionLine
+	Remotes         []string
+	CommitTypes     CommitTypes
+	MergeAdapters   MergeMessageAdapters
+	Files           []FileUpdater
+	Changelog       *Changelog
+	Logger          logrus.FieldLogger
 }
 
 // NewCalculateCommandBuilder creates a new instance of CalculateCommandBuilder.
@@ -51,6 +99,15 @@ func (b *CalculateCommandBuilder) SetPath(path string) *CalculateCommandBuilder
 	return b
 }
 
+// SetRepositoryURL sets the RepositoryURL field of the CalculateCommandBuilder.
+// When set, the repository is cloned from the URL into memory instead of being opened from Path.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetRepositoryURL(url string) *CalculateCommandBuilder {
+	b.RepositoryURL = url
+
+	return b
+}
+
 // SetAddFloatingTags sets the AddFloatingTags field of the CalculateCommandBuilder.
 // It takes a boolean parameter 'addFloatingTags' and assigns it to the 'AddFloatingTags' field of the CalculateCommandBuilder.
 // It returns a pointer to the CalculateCommandBuilder for method chaining.
@@ -60,6 +117,24 @@ func (b *CalculateCommandBuilder) SetAddFloatingTags(addFloatingTags bool) *Calc
 	return b
 }
 
+// SetAddLatestTag sets the AddLatestTag field of the CalculateCommandBuilder.
+// When enabled the latest tag follows the greatest stable release of the repository.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetAddLatestTag(addLatestTag bool) *CalculateCommandBuilder {
+	b.AddLatestTag = addLatestTag
+
+	return b
+}
+
+// SetAddStableTag sets the AddStableTag field of the CalculateCommandBuilder.
+// When enabled the stable tag follows the greatest stable release of the repository.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetAddStableTag(addStableTag bool) *CalculateCommandBuilder {
+	b.AddStableTag = addStableTag
+
+	return b
+}
+
 // SetPush sets the Push field of the CalculateCommandBuilder.
 // It takes a boolean parameter 'push' and assigns it to the 'Push' field of the CalculateCommandBuilder.
 // It returns a pointer to the CalculateCommandBuilder for method chaining.
@@ -78,17 +153,252 @@ func (b *CalculateCommandBuilder) SetDisableTagging(disableTagging bool) *Calcul
 	return b
 }
 
+// SetHistory sets the History field of the CalculateCommandBuilder.
+// The history mode selects the commits analyzed, every ancestor of HEAD when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetHistory(history HistoryMode) *CalculateCommandBuilder {
+	b.History = history
+
+	return b
+}
+
+// SetBaseTag sets the BaseTag field of the CalculateCommandBuilder.
+// The base tag policy selects the tag the next version is calculated from,
+// the greatest tag reachable from HEAD when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetBaseTag(policy BaseTagPolicy) *CalculateCommandBuilder {
+	b.BaseTag = policy
+
+	return b
+}
+
+// SetTagParser sets the TagParser field of the CalculateCommandBuilder.
+// The tag parser parses the names of the tags of the repository, and only accepts semantic versions
+// when it is not set. The names of the major and minor floating tags are reserved when floating tags are added.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetTagParser(parser TagParser) *CalculateCommandBuilder {
+	b.TagParser = parser
+
+	return b
+}
+
+// SetTagFilter sets the TagFilter field of the CalculateCommandBuilder.
+// The tag filter selects the tags considered as releases by their names, every tag when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetTagFilter(filter TagFilter) *CalculateCommandBuilder {
+	b.TagFilter = filter
+
+	return b
+}
+
+// SetStream sets the Stream field of the CalculateCommandBuilder.
+// Only the tags of the stream are considered as releases, and the release is tagged with its tag template,
+// so that each stream has its own sequence of versions. The default stream is tagged vX.Y.Z.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetStream(stream Stream) *CalculateCommandBuilder {
+	b.Stream = stream
+
+	return b
+}
+
+// SetDeepen sets the Deepen field of the CalculateCommandBuilder.
+// When enabled, shallow clones are deepened from the remote until a release tag is reachable.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetDeepen(deepen bool) *CalculateCommandBuilder {
+	b.Deepen = deepen
+
+	return b
+}
+
+// SetFetch sets the Fetch field of the CalculateCommandBuilder.
+// When enabled, the tags of the remote are fetched before the version is calculated.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetFetch(fetch bool) *CalculateCommandBuilder {
+	b.Fetch = fetch
+
+	return b
+}
+
+// SetPrune sets the Prune field of the CalculateCommandBuilder.
+// When enabled, local tags deleted from the remote are deleted before the version is calculated.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetPrune(prune bool) *CalculateCommandBuilder {
+	b.Prune = prune
+
+	return b
+}
+
+// SetRequireClean sets the RequireClean field of the CalculateCommandBuilder.
+// When enabled, tagging a working tree with uncommitted changes fails with ErrDirtyTree.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetRequireClean(requireClean bool) *CalculateCommandBuilder {
+	b.RequireClean = requireClean
+
+	return b
+}
+
+// SetCredentials sets the credentials used to authenticate against the remotes.
+// When not set, the credentials are read from the environment.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetCredentials(credentials Credentials) *CalculateCommandBuilder {
+	b.Credentials = &credentials
+
+	return b
+}
+
+// SetRemotes sets the Remotes field of the CalculateCommandBuilder.
+// Tags are pushed to every remote and the first remote is used for fetching. It defaults to origin.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetRemotes(remotes []string) *CalculateCommandBuilder {
+	b.Remotes = remotes
+
+	return b
+}
+
+// SetBranch sets the Branch field of the CalculateCommandBuilder.
+// It overrides the branch name taken from HEAD or the CI environment when matching branch rules.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetBranch(branch string) *CalculateCommandBuilder {
+	b.Branch = branch
+
+	return b
+}
+
+// SetBranchRules sets the BranchRules field of the CalculateCommandBuilder.
+// The first rule matching the branch decides the prerelease channel, bump cap, tagging and pushing.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetBranchRules(branchRules []BranchRule) *CalculateCommandBuilder {
+	b.BranchRules = branchRules
+
+	return b
+}
+
+// SetLine sets the Line field of the CalculateCommandBuilder.
+// Only tags inside the version line are used as the base version and the next version must stay within it.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetLine(line VersionLine) *CalculateCommandBuilder {
+	b.Line = line
+
+	return b
+}
+
+// SetCommitTypes sets the CommitTypes field of the CalculateCommandBuilder.
+// The commit types map conventional commit types to the version component they increment,
+// the default commit types are used when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetCommitTypes(commitTypes CommitTypes) *CalculateCommandBuilder {
+	b.CommitTypes = commitTypes
+
+	return b
+}
+
+// SetMergeAdapters sets the MergeAdapters field of the CalculateCommandBuilder.
+// The adapters extract the conventional commit messages from merge messages,
+// the default merge message adapters are used when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetMergeAdapters(adapters MergeMessageAdapters) *CalculateCommandBuilder {
+	b.MergeAdapters = adapters
+
+	return b
+}
+
+// SetFiles sets the Files field of the CalculateCommandBuilder.
+// The released version is written to the files, relative to the root of the repository at Path.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetFiles(files []FileUpdater) *CalculateCommandBuilder {
+	b.Files = files
+
+	return b
+}
+
+// SetChangelog sets the Changelog field of the CalculateCommandBuilder.
+// The commits of each release are added to the changelog, relative to the root of the repository at Path.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetChangelog(changelog *Changelog) *CalculateCommandBuilder {
+	b.Changelog = changelog
+
+	return b
+}
+
+// SetLogger sets the Logger field of the CalculateCommandBuilder.
+// The logger is also used by the Scm built by the builder, the shared logger is used when it is not set.
+// It returns a pointer to the CalculateCommandBuilder for method chaining.
+func (b *CalculateCommandBuilder) SetLogger(log logrus.FieldLogger) *CalculateCommandBuilder {
+	b.Logger = log
+
+	return b
+}
+
 // Build returns a Command built from the CalculateCommandBuilder.
 // It creates a CalculateCommandImpl with the provided Scm.
 func (b *CalculateCommandBuilder) Build() Command {
+	return b.BuildCalculateCommand()
+}
+
+// BuildCalculateCommand returns the CalculateCommandImpl built from the CalculateCommandBuilder,
+// giving access to its typed Calculate method.
+// When no Scm is provided, it opens the repository at Path, or clones the one at RepositoryURL.
+func (b *CalculateCommandBuilder) BuildCalculateCommand() *CalculateCommandImpl {
+	credentials := CredentialsFromEnvironment(os.Getenv)
+	if b.Credentials != nil {
+		credentials = *b.Credentials
+	}
+
+	tagParser := b.TagParser
+	tagParser.Floating = tagParser.Floating || b.AddFloatingTags
+	tagParser.Filter = b.TagFilter
+	tagParser.Stream = b.Stream
+
+	if b.Scm == nil && b.RepositoryURL != "" {
+		b.Scm = NewRemoteScmGitBuilder().
+			SetURL(b.RepositoryURL).
+			SetDeepen(b.Deepen).
+			SetHistory(b.History).
+			SetTagParser(tagParser).
+			SetCredentials(credentials).
+			SetLogger(b.Logger).
+			Build()
+	}
+
 	if b.Scm == nil {
-		b.Scm = NewScmGitBuilder().SetPath(b.Path).Build()
+		b.Scm = NewScmGitBuilder().
+			SetPath(b.Path).
+			SetDeepen(b.Deepen).
+			SetHistory(b.History).
+			SetTagParser(tagParser).
+			SetRemotes(b.Remotes).
+			SetCredentials(credentials).
+			SetLogger(b.Logger).
+			Build()
+	}
+
+	worktree := b.Path
+	if b.RepositoryURL != "" {
+		worktree = "" // A repository cloned into memory has no files to update
 	}
 
 	return &CalculateCommandImpl{
 		Scm:             b.Scm,
+		Path:            worktree,
 		AddFloatingTags: b.AddFloatingTags,
+		AddLatestTag:    b.AddLatestTag,
+		AddStableTag:    b.AddStableTag,
 		Push:            b.Push,
+		DisableTagging:  b.DisableTagging,
+		Fetch:           b.Fetch,
+		Prune:           b.Prune,
+		RequireClean:    b.RequireClean,
+		Branch:          b.Branch,
+		CIBranch:        BranchFromEnvironment(os.Getenv),
+		BranchRules:     b.BranchRules,
+		Line:            b.Line,
+		BaseTag:         b.BaseTag,
+		Stream:          b.Stream,
+		CommitTypes:     b.CommitTypes,
+		MergeAdapters:   b.MergeAdapters,
+		Files:           b.Files,
+		Changelog:       b.Changelog,
+		Logger:          b.Logger,
 	}
 }
 
@@ -97,83 +407,444 @@ func (b *CalculateCommandBuilder) Build() Command {
 type CalculateCommandImpl struct {
 	Command
 	Scm             Scm
+	Path            string // Path inside the working tree of the files and the changelog, empty without a working tree.
 	AddFloatingTags bool
+	AddLatestTag    bool // Moves the latest tag when the version is the greatest stable release.
+	AddStableTag    bool // Moves the stable tag when the version is the greatest stable release.
 	Push            bool
 	DisableTagging  bool
+	Fetch           bool                 // Fetches the tags of the remote before calculating.
+	Prune           bool                 // Deletes local tags deleted from the remote when fetching.
+	RequireClean    bool                 // Fails with ErrDirtyTree instead of tagging a working tree with uncommitted changes.
+	Branch          string               // Branch name overriding the one checked out at HEAD.
+	CIBranch        string               // Branch name from the CI environment, used when HEAD is detached.
+	BranchRules     []BranchRule         // Release rules per branch pattern.
+	Line            VersionLine          // Versions the release must stay within, overriding the branch rule.
+	BaseTag         BaseTagPolicy        // Selects the tag the next version is calculated from.
+	Stream          Stream               // Stream whose tag template names the release tags.
+	CommitTypes     CommitTypes          // Version component incremented per commit type, the default types when nil.
+	MergeAdapters   MergeMessageAdapters // Adapters of merge messages, the default adapters when nil.
+	Files           []FileUpdater        // Files the released version is written to.
+	Changelog       *Changelog           // Changelog the commits of each release are added to, none when nil.
+	Logger          logrus.FieldLogger   // Logger of the progress and ignored errors, the shared logger when nil.
 }
 
-// Execute executes the CalculateCommandImpl command and returns the next version tag string and any error encountered.
-func (c *CalculateCommandImpl) Execute() (interface{}, error) {
-	var output CalculateOutput
+// releasePolicy holds the release settings in effect for the current branch.
+type releasePolicy struct {
+	channel        []semver.PRVersion       // Prerelease channel, empty for stable releases.
+	maxBump        SemanticVersionComponent // Largest component a release may increment.
+	line           VersionLine              // Versions the release must stay within.
+	restrictHead   bool                     // Only reuse HEAD tags matching the channel and line.
+	disableTagging bool
+	push           bool
+}
 
-	commitLogs, err := c.Scm.GetCommitLog()
-	if err != nil {
+// Execute executes the CalculateCommandImpl command and returns the CalculateOutput and any error encountered.
+// It returns an empty string instead of the output when the calculation fails before any tag is created.
+func (c *CalculateCommandImpl) Execute(ctx context.Context) (interface{}, error) {
+	output, err := c.Calculate(ctx)
+	if output == nil {
 		return "", err
 	}
 
-	nextTag := c.calculateTag(commitLogs)
+	return *output, err
+}
+
+// Calculate calculates the next version and releases it, returning the typed output.
+// When fetching or pushing, the tags of the remote are fetched first. If another release took the version in
+// the meantime, the tags of the run are rolled back and the release is retried with the next version that is
+// not tagged yet, unless a remote already accepted the version tag: the release then fails, because retrying
+// would publish another version of the same commit. The output reports the tag changes of the fetch of the last
+// attempt. It is returned together with the error when a release fails after tags were created,
+// so that the rolled back tags are reported; otherwise it is nil on error.
+// Canceling the context stops the calculation or the push in progress, and the tags created by the run are
+// rolled back before the context error is returned.
+func (c *CalculateCommandImpl) Calculate(ctx context.Context) (*CalculateOutput, error) {
+	if c.Path == "" && (len(c.Files) > 0 || c.Changelog != nil) {
+		return nil, fmt.Errorf("%w: files and changelog need the working tree of a local repository", ErrInvalidConfig)
+	}
+
+	for attempt := 1; ; attempt++ {
+		var fetched []TagChange
 
-	if c.AddFloatingTags {
-		floatingVersionMajor := strconv.FormatInt(int64(nextTag.Major), 10)
+		if c.Fetch || c.Prune || (c.Push && !c.DisableTagging) {
+			var err error
 
-		if !c.DisableTagging {
-			err = c.Scm.Tag(versionPrefix+floatingVersionMajor, commitLogs[0].Hash, true) // vx
+			fetched, err = c.Scm.FetchTags(ctx, c.Prune)
 			if err != nil {
-				logger.GetInstance().Println(err)
+				return nil, err
 			}
 		}
 
-		output.FloatingVersionMajor = floatingVersionMajor
+		output, err := c.calculate(ctx, fetched, attempt > 1)
+		if !errors.Is(err, ErrTagConflict) || attempt == maxReleaseAttempts {
+			return output, err
+		}
 
-		floatingVersionMinor := floatingVersionMajor + "." + strconv.FormatInt(int64(nextTag.Minor), 10)
+		if c.versionTagPublished(output) {
+			c.log().Errorln("Not retrying the release, a remote already has the version tag:", err)
 
-		if !c.DisableTagging {
-			err = c.Scm.Tag(versionPrefix+floatingVersionMinor, commitLogs[0].Hash, true) // vx.y
-			if err != nil {
-				logger.GetInstance().Println(err)
-			}
+			return output, err
 		}
 
-		output.FloatingVersionMinor = floatingVersionMinor
+		c.log().Warnln("Retrying release after a tag conflict:", err)
+	}
+}
+
+// versionTagPublished reports whether a remote accepted the version tag of the output, or already had it.
+func (c *CalculateCommandImpl) versionTagPublished(output *CalculateOutput) bool {
+	if output == nil {
+		return false
+	}
+
+	ref := plumbing.NewTagReferenceName(c.Stream.TagName(output.NextVersion)).String()
+
+	for _, result := range output.Pushed {
+		if result.Ref == ref && (result.Status == PushStatusPushed || result.Status == PushStatusUpToDate) {
+			return true
+		}
 	}
 
-	if !c.DisableTagging {
-		err = c.Scm.Tag(versionPrefix+nextTag.String(), commitLogs[0].Hash, false) // vx.y.z
+	return false
+}
+
+// calculate calculates and releases the next version once, reporting the fetched tag changes in the output.
+// When avoidTaken is set, a version already tagged on another commit is replaced by the next one that is
+// not tagged yet.
+func (c *CalculateCommandImpl) calculate(ctx context.Context, fetched []TagChange, avoidTaken bool) (*CalculateOutput, error) {
+	output := &CalculateOutput{APIVersion: APIVersion, Stream: c.Stream.Name, Fetched: fetched}
+
+	commitLogs, err := c.Scm.GetCommitLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commitLogs) == 0 {
+		return nil, ErrNoCommits
+	}
+
+	err = c.reportLegacyTags(output)
+	if err != nil {
+		return nil, err
+	}
+
+	output.Branch = c.resolveBranch(commitLogs)
+
+	policy, err := c.releasePolicy(output.Branch)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.RequireClean && !policy.disableTagging {
+		err = c.checkCleanTree(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nextTag, err := c.calculateTag(output, commitLogs, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !output.Released {
+		output.NextVersion = nextTag.String()
+		c.log().Debugln("No commit since", output.NextVersion, "requires a release")
+
+		return output, nil
+	}
+
+	if avoidTaken && !containsVersion(commitLogs[0].Tags, *nextTag) {
+		nextTag, err = c.untakenVersion(*nextTag, policy)
 		if err != nil {
-			logger.GetInstance().Println(err)
+			return nil, err
+		}
+	}
+
+	if c.AddFloatingTags || c.AddLatestTag || c.AddStableTag {
+		err = c.moveFloatingTags(ctx, output, *nextTag, commitLogs[0].Hash, policy)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	output.NextVersion = nextTag.String()
 
-	if c.Push && !c.DisableTagging {
-		err = c.Scm.Push()
+	if policy.disableTagging {
+		return output, nil
+	}
+
+	err = c.release(ctx, output, *nextTag, commitLogs[0].Hash, policy)
+	if err != nil {
+		c.log().Warnln("Rolling back the release:", err)
+		c.rollback(output)
+
+		return output, err
+	}
+
+	return output, c.updateFiles(output, commitLogs[0].Date)
+}
+
+// log returns the logger of the CalculateCommandImpl.
+func (c *CalculateCommandImpl) log() logrus.FieldLogger {
+	return orDefaultLogger(c.Logger)
+}
+
+// reportLegacyTags reports in the output the legacy tags that were normalized and the tags that were ignored,
+// when the Scm reports them.
+func (c *CalculateCommandImpl) reportLegacyTags(output *CalculateOutput) error {
+	reporter, ok := c.Scm.(LegacyTagReporter)
+	if !ok {
+		return nil
+	}
+
+	var err error
+
+	output.NormalizedTags, output.IgnoredTags, err = reporter.LegacyTags()
+
+	return err
+}
+
+// checkCleanTree returns ErrDirtyTree when the working tree has uncommitted changes.
+func (c *CalculateCommandImpl) checkCleanTree(ctx context.Context) error {
+	clean, err := c.Scm.IsClean(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !clean {
+		return ErrDirtyTree
+	}
+
+	return nil
+}
+
+// release creates the version tag and pushes the tags created in this run when pushing is enabled.
+// A version tag that already exists locally is not an error, so that a released HEAD can be calculated again.
+func (c *CalculateCommandImpl) release(ctx context.Context, output *CalculateOutput, nextTag semver.Version, hash string,
+	policy releasePolicy,
+) error {
+	name := c.Stream.TagName(nextTag.String())
+
+	err := c.Scm.Tag(ctx, name, hash, false) // vx.y.z
+	if errors.Is(err, git.ErrTagExists) {
+		c.log().Debugln(err)
+	} else if err != nil {
+		return err
+	} else {
+		output.TagsCreated = append(output.TagsCreated, name)
+	}
+
+	if !policy.push {
+		return nil
+	}
+
+	output.Pushed, err = c.Scm.Push(ctx)
+
+	return err
+}
+
+// updateFiles writes the released version to the files and adds the released commits to the changelog,
+// reporting the files changed in the output. The tags of the release are kept when a file cannot be updated.
+func (c *CalculateCommandImpl) updateFiles(output *CalculateOutput, date time.Time) error {
+	if len(c.Files) == 0 && c.Changelog == nil {
+		return nil
+	}
+
+	root, err := FindRepositoryRoot(c.Path)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range c.Files {
+		err = file.Update(root, output.NextVersion)
+		if err != nil {
+			return err
+		}
+
+		output.FilesUpdated = append(output.FilesUpdated, filepath.ToSlash(file.Path))
+	}
+
+	if c.Changelog == nil || len(output.Commits) == 0 {
+		return nil // A HEAD released by an earlier run has no commits to add
+	}
+
+	commits := WithoutReverts(c.mergeAdapters().AdaptCommits(output.Commits))
+
+	updated, err := c.Changelog.Update(root, c.Stream.TagName(output.NextVersion), date, commits)
+	if updated {
+		output.FilesUpdated = append(output.FilesUpdated, filepath.ToSlash(c.Changelog.Path))
+	}
+
+	return err
+}
+
+// mergeAdapters returns the adapters of merge messages, the default adapters when none are set.
+func (c *CalculateCommandImpl) mergeAdapters() MergeMessageAdapters {
+	if c.MergeAdapters == nil {
+		return DefaultMergeMessageAdapters()
+	}
+
+	return c.MergeAdapters
+}
+
+// rollback restores the tags created by a failed release and reports the outcome in the output.
+func (c *CalculateCommandImpl) rollback(output *CalculateOutput) {
+	var err error
+
+	output.TagsCreated = nil
+	output.RolledBack, err = c.Scm.Rollback()
+	if err != nil {
+		c.log().Errorln(err)
+	}
+}
+
+// moveFloatingTags moves the requested floating tags that still follow the greatest release in their
+// scope to the commit, and reports in the output which floating tags moved and which were skipped.
+func (c *CalculateCommandImpl) moveFloatingTags(ctx context.Context, output *CalculateOutput, nextTag semver.Version,
+	hash string, policy releasePolicy,
+) error {
+	if c.AddFloatingTags && len(nextTag.Pre) == 0 {
+		output.FloatingVersionMajor = strconv.FormatUint(nextTag.Major, 10)
+		output.FloatingVersionMinor = output.FloatingVersionMajor + "." + strconv.FormatUint(nextTag.Minor, 10)
+	}
+
+	candidates := floatingTags(nextTag, c.Stream, c.AddFloatingTags, c.AddLatestTag, c.AddStableTag)
+
+	existingTags := []*semver.Version{}
+
+	if len(nextTag.Pre) == 0 {
+		var err error
+
+		existingTags, err = c.Scm.GetTags()
 		if err != nil {
-			logger.GetInstance().Println(err)
+			return err
+		}
+	}
 
-			return "", err
+	output.FloatingTagsMoved, output.FloatingTagsSkipped = planFloatingTags(nextTag, candidates, existingTags)
+
+	if policy.disableTagging {
+		return nil
+	}
+
+	for _, name := range output.FloatingTagsMoved {
+		err := c.Scm.Tag(ctx, name, hash, true)
+		if err != nil {
+			c.log().Warnln(err)
+
+			continue
 		}
+
+		output.TagsCreated = append(output.TagsCreated, name)
+	}
+
+	return nil
+}
+
+// resolveBranch returns the branch used to match branch rules.
+// An explicit branch takes precedence over the branch checked out at HEAD,
+// which takes precedence over the branch reported by the CI environment.
+func (c *CalculateCommandImpl) resolveBranch(commitLogs []*CommitLog) string {
+	if c.Branch != "" {
+		return c.Branch
 	}
 
-	return output, nil
+	if len(commitLogs) > 0 && commitLogs[0].BranchName != "" {
+		return commitLogs[0].BranchName
+	}
+
+	return c.CIBranch
+}
+
+// releasePolicy combines the command settings with the branch rule matching the branch.
+// The command version line takes precedence over the one of the branch rule. It returns
+// ErrInvalidBranchRule when the prerelease channel of the rule is not valid for the branch.
+func (c *CalculateCommandImpl) releasePolicy(branch string) (releasePolicy, error) {
+	policy := releasePolicy{
+		maxBump:        MAJOR,
+		line:           c.Line,
+		restrictHead:   c.Line != "",
+		disableTagging: c.DisableTagging,
+		push:           c.Push,
+	}
+
+	rule := MatchBranchRule(c.BranchRules, branch)
+	if rule == nil {
+		return policy, nil
+	}
+
+	channel, err := rule.Channel(branch)
+	if err != nil {
+		return policy, err
+	}
+
+	policy.channel = channel
+	policy.maxBump = rule.MaxBump
+	policy.restrictHead = true
+	policy.disableTagging = policy.disableTagging || rule.DisableTagging
+	policy.push = policy.push && !rule.DisablePush
+
+	if policy.line == "" {
+		policy.line = rule.Line
+	}
+
+	return policy, nil
 }
 
 // calculateTag calculates the next version tag based on the commit logs.
-// It takes a slice of CommitLog pointers and returns a pointer to the calculated semver.Version.
-func (c *CalculateCommandImpl) calculateTag(commitLogs []*CommitLog) *semver.Version {
+// It takes a slice of CommitLog pointers and the release policy of the current branch,
+// and returns a pointer to the calculated semver.Version. The previous version, the bump
+// and the released commits are reported in the output, unless HEAD is already released.
+// Prerelease tags and tags outside the version line are never used as the base version;
+// when the policy has a prerelease channel the next version gets the channel and the next
+// free prerelease number. It returns ErrVersionOutsideLine when the bump would leave the line.
+func (c *CalculateCommandImpl) calculateTag(output *CalculateOutput, commitLogs []*CommitLog,
+	policy releasePolicy,
+) (*semver.Version, error) {
 	nextTag, _ := semver.Make("0.0.0")
 
 	if len(commitLogs) > 0 && len(commitLogs[0].Tags) > 0 {
-		nextTag = c.GetGreatestTag(nextTag, commitLogs[0].Tags)
+		headTags := commitLogs[0].Tags
+		if policy.restrictHead {
+			headTags = policy.line.Filter(channelTags(headTags, policy.channel))
+		}
+
+		if len(headTags) > 0 {
+			nextTag = c.GetGreatestTag(nextTag, headTags)
+			output.Released = true
 
-		return &nextTag
+			return &nextTag, nil
+		}
 	}
 
-	for _, commit := range commitLogs {
-		nextTag = c.GetGreatestTag(nextTag, commit.Tags)
+	nextTag, reachable, err := c.baseVersion(output, commitLogs, policy.line)
+	if err != nil {
+		return nil, err
 	}
 
-	updateType := GetVersionUpdate(commitLogs[0].Message)
+	commits, found := commitsSince(commitLogs, reachable)
+	if found || nextTag.GT(reachable) {
+		output.PreviousVersion = nextTag.String()
+	}
+
+	commitTypes := c.CommitTypes
+	if commitTypes == nil {
+		commitTypes = DefaultCommitTypes()
+	}
+
+	bumped := commits
+	if len(bumped) == 0 {
+		bumped = commitLogs[:1] // A HEAD tagged outside the channel or the line is bumped by its own message
+	}
+
+	updateType := commitTypes.Bump(c.mergeAdapters().AdaptCommits(bumped))
+	if updateType < policy.maxBump {
+		updateType = policy.maxBump
+	}
+
+	output.Bump = updateType.String()
+	output.Commits = commits
 
 	switch updateType {
 	case MAJOR:
@@ -182,11 +853,24 @@ func (c *CalculateCommandImpl) calculateTag(commitLogs []*CommitLog) *semver.Ver
 		nextTag.IncrementMinor() //nolint: errcheck
 	case PATCH:
 		nextTag.IncrementPatch() //nolint: errcheck
+	case NONE:
+		return &nextTag, nil
 	default:
 		nextTag.IncrementPatch() //nolint: errcheck
 	}
 
-	return &nextTag
+	if !policy.line.Contains(nextTag) {
+		return nil, fmt.Errorf("%w: a %s bump gives %s, which is outside of %s",
+			ErrVersionOutsideLine, updateType, nextTag, policy.line)
+	}
+
+	output.Released = true
+
+	if len(policy.channel) > 0 {
+		nextTag.Pre = nextPrerelease(nextTag, policy.channel, commitLogs)
+	}
+
+	return &nextTag, nil
 }
 
 // GetGreatestTag returns the greatest tag from a list of tags.
@@ -200,3 +884,132 @@ func (c *CalculateCommandImpl) GetGreatestTag(nextTag semver.Version, tags []*se
 
 	return nextTag
 }
+
+// untakenVersion returns the version, or the next patch release or prerelease number when the version
+// is already tagged, which happens when a concurrent release tagged it on another commit.
+func (c *CalculateCommandImpl) untakenVersion(version semver.Version, policy releasePolicy) (*semver.Version, error) {
+	tags, err := c.Scm.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	for containsVersion(tags, version) {
+		if len(version.Pre) > 0 {
+			version.Pre[len(version.Pre)-1].VersionNum++
+		} else {
+			version.Patch++
+		}
+	}
+
+	if !policy.line.Contains(version) {
+		return nil, fmt.Errorf("%w: the next untagged version %s is outside of %s", ErrVersionOutsideLine, version, policy.line)
+	}
+
+	return &version, nil
+}
+
+// commitsSince returns the commits reachable from HEAD but not from the commits tagged with the version,
+// like git rev-list base..HEAD, in the order of the commit logs, and whether a commit tagged with the version
+// was found. The commits of a branch merged after the tagged commit are included whatever the order of the
+// walk. Without a tagged commit every commit is returned.
+func commitsSince(commitLogs []*CommitLog, version semver.Version) ([]*CommitLog, bool) {
+	byHash := make(map[string]*CommitLog, len(commitLogs))
+	released := map[*CommitLog]bool{}
+	pending := []*CommitLog{}
+
+	for _, commit := range commitLogs {
+		byHash[commit.Hash] = commit
+
+		if containsVersion(commit.Tags, version) {
+			released[commit] = true
+			pending = append(pending, commit)
+		}
+	}
+
+	if len(pending) == 0 {
+		return commitLogs, false
+	}
+
+	for len(pending) > 0 {
+		commit := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		for _, hash := range commit.Parents {
+			parent, walked := byHash[hash]
+			if walked && !released[parent] {
+				released[parent] = true
+				pending = append(pending, parent)
+			}
+		}
+	}
+
+	commits := []*CommitLog{}
+
+	for _, commit := range commitLogs {
+		if !released[commit] {
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits, true
+}
+
+// containsVersion reports whether the version is one of the tags.
+func containsVersion(tags []*semver.Version, version semver.Version) bool {
+	for _, tag := range tags {
+		if tag.Equals(version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// channelTags returns the tags released on the given prerelease channel.
+// A nil channel selects stable releases, which have no prerelease identifiers.
+func channelTags(tags []*semver.Version, channel []semver.PRVersion) []*semver.Version {
+	selected := []*semver.Version{}
+
+	for _, tag := range tags {
+		if len(channel) == 0 && len(tag.Pre) == 0 {
+			selected = append(selected, tag)
+		} else if len(channel) > 0 && isOnChannel(tag, channel) {
+			selected = append(selected, tag)
+		}
+	}
+
+	return selected
+}
+
+// isOnChannel reports whether the tag is a prerelease of the channel followed by a number, like 1.2.0-beta.3.
+func isOnChannel(tag *semver.Version, channel []semver.PRVersion) bool {
+	if len(tag.Pre) != len(channel)+1 || !tag.Pre[len(channel)].IsNum {
+		return false
+	}
+
+	for i, identifier := range channel {
+		if tag.Pre[i].Compare(identifier) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nextPrerelease returns the prerelease identifiers for version on the channel,
+// numbered one above the greatest existing prerelease of the same version and channel.
+func nextPrerelease(version semver.Version, channel []semver.PRVersion, commitLogs []*CommitLog) []semver.PRVersion {
+	number := uint64(0)
+
+	for _, commit := range commitLogs {
+		for _, tag := range channelTags(commit.Tags, channel) {
+			if tag.Major == version.Major && tag.Minor == version.Minor && tag.Patch == version.Patch {
+				number = max(number, tag.Pre[len(channel)].VersionNum)
+			}
+		}
+	}
+
+	pre := append([]semver.PRVersion{}, channel...)
+
+	return append(pre, semver.PRVersion{VersionNum: number + 1, IsNum: true})
+}
@@ -1,13 +1,20 @@
 package core_test
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
 
 	"github.com/blang/semver/v4"
+	"github.com/go-git/go-git/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var errExpectedFromTest = errors.New("some error")
@@ -20,9 +27,10 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldNotBumpVersion(
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{
 		{
 			Tags: []*semver.Version{
 				{Major: 1, Minor: 0, Patch: 0},
@@ -31,20 +39,29 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldNotBumpVersion(
 			},
 		},
 	}, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v2", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0.2", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0.2", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "2.0.2", FloatingVersionMajor: "2", FloatingVersionMinor: "2.0"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		NextVersion:          "2.0.2",
+		FloatingVersionMajor: "2",
+		FloatingVersionMinor: "2.0",
+		FloatingTagsMoved:    []string{"v2", "v2.0"},
+		TagsCreated:          []string{"v2", "v2.0", "v2.0.2"},
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -56,9 +73,10 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldIncreaseMayor(t
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "feat!: add new feature",
@@ -70,21 +88,34 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldIncreaseMayor(t
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v3", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v3.0", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v3.0.0", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3.0", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3.0.0", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "3.0.0", FloatingVersionMajor: "3", FloatingVersionMinor: "3.0"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "3.0.0",
+		Bump:                 "major",
+		FloatingVersionMajor: "3",
+		FloatingVersionMinor: "3.0",
+		FloatingTagsMoved:    []string{"v3", "v3.0"},
+		TagsCreated:          []string{"v3", "v3.0", "v3.0.0"},
+		Commits:              commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -96,9 +127,10 @@ func TestCalculateCommandImpl_ShouldIncreaseMinor(t *testing.T) {
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "feat: add new feature",
@@ -110,21 +142,34 @@ func TestCalculateCommandImpl_ShouldIncreaseMinor(t *testing.T) {
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v2", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.1", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.1.0", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.1", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.1.0", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "2.1.0", FloatingVersionMajor: "2", FloatingVersionMinor: "2.1"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "2.1.0",
+		Bump:                 "minor",
+		FloatingVersionMajor: "2",
+		FloatingVersionMinor: "2.1",
+		FloatingTagsMoved:    []string{"v2", "v2.1"},
+		TagsCreated:          []string{"v2", "v2.1", "v2.1.0"},
+		Commits:              commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -136,9 +181,10 @@ func TestCalculateCommandImpl_ShouldIncreasePatch(t *testing.T) {
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "fix: add new feature",
@@ -150,21 +196,34 @@ func TestCalculateCommandImpl_ShouldIncreasePatch(t *testing.T) {
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v2", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0.3", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0.3", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "2.0.3", FloatingVersionMajor: "2", FloatingVersionMinor: "2.0"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "2.0.3",
+		Bump:                 "patch",
+		FloatingVersionMajor: "2",
+		FloatingVersionMinor: "2.0",
+		FloatingTagsMoved:    []string{"v2", "v2.0"},
+		TagsCreated:          []string{"v2", "v2.0", "v2.0.3"},
+		Commits:              commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -176,9 +235,10 @@ func TestCalculateCommandImpl_ShouldIncreasePatchIfByDefault(t *testing.T) {
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "whatever: add new feature",
@@ -190,21 +250,34 @@ func TestCalculateCommandImpl_ShouldIncreasePatchIfByDefault(t *testing.T) {
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v2", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.0.3", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0.3", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "2.0.3", FloatingVersionMajor: "2", FloatingVersionMinor: "2.0"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "2.0.3",
+		Bump:                 "patch",
+		FloatingVersionMajor: "2",
+		FloatingVersionMinor: "2.0",
+		FloatingTagsMoved:    []string{"v2", "v2.0"},
+		TagsCreated:          []string{"v2", "v2.0", "v2.0.3"},
+		Commits:              commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -216,9 +289,10 @@ func TestCalculateCommandImpl_ShouldReturnNextVersion(t *testing.T) {
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "feat: add new feature",
@@ -230,21 +304,34 @@ func TestCalculateCommandImpl_ShouldReturnNextVersion(t *testing.T) {
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v2", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.1", gomock.Any(), true).Return(nil).Times(1)
-	mockScm.EXPECT().Tag("v2.1.0", gomock.Any(), false).Return(nil).Times(1)
-	mockScm.EXPECT().Push().Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.1", gomock.Any(), true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.1.0", gomock.Any(), false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "2.1.0", FloatingVersionMajor: "2", FloatingVersionMinor: "2.1"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "2.1.0",
+		Bump:                 "minor",
+		FloatingVersionMajor: "2",
+		FloatingVersionMinor: "2.1",
+		FloatingTagsMoved:    []string{"v2", "v2.1"},
+		TagsCreated:          []string{"v2", "v2.1", "v2.1.0"},
+		Commits:              commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
 
@@ -256,15 +343,16 @@ func TestCalculateCommandImpl_ShouldReturnError(t *testing.T) {
 
 	// Create a mock Scm
 	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return(nil, errExpectedFromTest).Times(1)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(nil, errExpectedFromTest).Times(1)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
 
 	// Call Execute method
-	result, resultError := calculateCommand.Execute()
+	result, resultError := calculateCommand.Execute(context.Background())
 
 	// Assert the result when there's an error
 	assert.Equal(t, resultError, errExpectedFromTest)
@@ -407,7 +495,7 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldIncreaseMayorBu
 	mockScm := core.NewMockScm(ctrl)
 
 	// Set up expectations for GetCommitLog method
-	mockScm.EXPECT().GetCommitLog().Return([]*core.CommitLog{
+	commitLogs := []*core.CommitLog{
 		{
 			Tags:    []*semver.Version{},
 			Message: "feat!: add new feature",
@@ -419,20 +507,1080 @@ func TestCalculateCommandImpl_ShouldReturnSameTaggedVersionShouldIncreaseMayorBu
 				{Major: 2, Minor: 0, Patch: 1},
 			},
 		},
-	}, nil)
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
 
-	mockScm.EXPECT().Tag("v3", gomock.Any(), true).Return(nil).Times(0)
-	mockScm.EXPECT().Tag("v3.0", gomock.Any(), true).Return(nil).Times(0)
-	mockScm.EXPECT().Tag("v3.0.0", gomock.Any(), false).Return(nil).Times(0)
-	mockScm.EXPECT().Push().Return(nil).Times(0)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3", gomock.Any(), true).Return(nil).Times(0)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3.0", gomock.Any(), true).Return(nil).Times(0)
+	mockScm.EXPECT().Tag(gomock.Any(), "v3.0.0", gomock.Any(), false).Return(nil).Times(0)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(0)
 
 	// Create CalculateCommandImpl with the mock Scm
 	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true, DisableTagging: true}
 
 	// Call Execute method
-	result, err := calculateCommand.Execute()
+	result, err := calculateCommand.Execute(context.Background())
 
 	// Assert the result
-	assert.Equal(t, core.CalculateOutput{NextVersion: "3.0.0", FloatingVersionMajor: "3", FloatingVersionMinor: "3.0"}, result)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "2.0.2",
+		NextVersion:          "3.0.0",
+		Bump:                 "major",
+		FloatingVersionMajor: "3",
+		FloatingVersionMinor: "3.0",
+		FloatingTagsMoved:    []string{"v3", "v3.0"},
+		Commits:              commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldNumberPrereleasesOnBranchChannel(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	beta1 := semver.MustParse("1.3.0-beta.1")
+	beta2 := semver.MustParse("1.3.0-beta.2")
+	stable := semver.MustParse("1.2.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c3", Message: "feat: add another feature", BranchName: "develop"},
+		{Hash: "c2", Tags: []*semver.Version{&beta2}},
+		{Hash: "c1", Tags: []*semver.Version{&beta1}},
+		{Hash: "c0", Tags: []*semver.Version{&stable}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.3.0-beta.3", "c3", false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil).Times(1)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:             mockScm,
+		AddFloatingTags: true,
+		Push:            true,
+		BranchRules: []core.BranchRule{
+			{Pattern: "main"},
+			{Pattern: "develop", Prerelease: "beta"},
+		},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:          core.APIVersion,
+		Released:            true,
+		PreviousVersion:     "1.2.0",
+		NextVersion:         "1.3.0-beta.3",
+		Bump:                "minor",
+		FloatingTagsSkipped: []string{"v1", "v1.3"},
+		Branch:              "develop",
+		TagsCreated:         []string{"v1.3.0-beta.3"},
+		Commits:             commitLogs[:3],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldFailWhenChannelOfBranchIsInvalid(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	stable := semver.MustParse("1.2.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{
+		{Hash: "c1", Message: "feat: add a feature", BranchName: "___"},
+		{Hash: "c0", Tags: []*semver.Version{&stable}},
+	}, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:         mockScm,
+		BranchRules: []core.BranchRule{{Pattern: "*", Prerelease: "{slug}"}},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Empty(t, result)
+	assert.ErrorIs(t, err, core.ErrInvalidBranchRule)
+}
+
+func TestCalculateCommandImpl_ShouldReleaseStableIgnoringPrereleaseTags(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	beta := semver.MustParse("1.3.0-beta.2")
+	stable := semver.MustParse("1.2.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c2", Message: "feat: merge develop", Tags: []*semver.Version{&beta}},
+		{Hash: "c1", Tags: []*semver.Version{&stable}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "v1", "c2", true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.3", "c2", true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.3.0", "c2", false).Return(nil).Times(1)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:             mockScm,
+		AddFloatingTags: true,
+		Branch:          "main",
+		BranchRules:     []core.BranchRule{{Pattern: "main"}},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "1.2.0",
+		NextVersion:          "1.3.0",
+		Bump:                 "minor",
+		FloatingVersionMajor: "1",
+		FloatingVersionMinor: "1.3",
+		FloatingTagsMoved:    []string{"v1", "v1.3"},
+		Branch:               "main",
+		TagsCreated:          []string{"v1", "v1.3", "v1.3.0"},
+		Commits:              commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldNotPushFeatureBranchPrereleases(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	stable := semver.MustParse("2.0.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "fix: login form"},
+		{Hash: "c0", Tags: []*semver.Version{&stable}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.0.1-feat-login.1", "c1", false).Return(nil).Times(1)
+	mockScm.EXPECT().Push(gomock.Any()).Times(0)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:      mockScm,
+		Push:     true,
+		CIBranch: "feature/login",
+		BranchRules: []core.BranchRule{
+			{Pattern: "feature/*", Prerelease: "feat-{slug}", DisablePush: true},
+		},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:      core.APIVersion,
+		Released:        true,
+		PreviousVersion: "2.0.0",
+		NextVersion:     "2.0.1-feat-login.1",
+		Bump:            "patch",
+		Branch:          "feature/login",
+		TagsCreated:     []string{"v2.0.1-feat-login.1"},
+		Commits:         commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldCapBumpOnMaintenanceBranch(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	stable := semver.MustParse("1.4.2")
+
+	mockScm := core.NewMockScm(ctrl)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "feat!: breaking change backported", BranchName: "release/1.x"},
+		{Hash: "c0", Tags: []*semver.Version{&stable}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm: mockScm,
+		BranchRules: []core.BranchRule{
+			{Pattern: "release/*", MaxBump: core.MINOR, DisableTagging: true},
+		},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:      core.APIVersion,
+		Released:        true,
+		PreviousVersion: "1.4.2",
+		NextVersion:     "1.5.0",
+		Bump:            "minor",
+		Branch:          "release/1.x",
+		Commits:         commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldOnlyUseTagsInsideVersionLine(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	maintenance := semver.MustParse("1.4.6")
+	current := semver.MustParse("3.2.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c2", Message: "fix: backport security fix"},
+		{Hash: "c1", Tags: []*semver.Version{&current}},
+		{Hash: "c0", Tags: []*semver.Version{&maintenance}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.4.7", "c2", false).Return(nil).Times(1)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Line: "1.x"}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:      core.APIVersion,
+		Released:        true,
+		PreviousVersion: "1.4.6",
+		NextVersion:     "1.4.7",
+		Bump:            "patch",
+		TagsCreated:     []string{"v1.4.7"},
+		Commits:         commitLogs[:2],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldRejectBumpLeavingVersionLine(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	maintenance := semver.MustParse("1.4.6")
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{
+		{Hash: "c1", Message: "feat!: drop legacy api", BranchName: "release/1.x"},
+		{Hash: "c0", Tags: []*semver.Version{&maintenance}},
+	}, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockScm.EXPECT().Push(gomock.Any()).Times(0)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:         mockScm,
+		Push:        true,
+		BranchRules: []core.BranchRule{{Pattern: "release/1.x", Line: "1.x"}},
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Empty(t, result)
+	assert.ErrorIs(t, err, core.ErrVersionOutsideLine)
+	assert.ErrorContains(t, err, "a major bump gives 2.0.0, which is outside of 1.x")
+}
+
+func TestCalculateCommandImpl_ShouldNotMoveFloatingTagsToBackports(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	backportBase := semver.MustParse("1.4.6")
+	newerMinor := semver.MustParse("1.5.0")
+	newerMajor := semver.MustParse("2.0.0")
+
+	mockScm := core.NewMockScm(ctrl)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "fix: backport security fix"},
+		{Hash: "c0", Tags: []*semver.Version{&backportBase}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return([]*semver.Version{&backportBase, &newerMinor, &newerMajor}, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.4", "c1", true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.4.7", "c1", false).Return(nil).Times(1)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:             mockScm,
+		AddFloatingTags: true,
+		AddLatestTag:    true,
+		AddStableTag:    true,
+		Line:            "1.4.x",
+	}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		PreviousVersion:      "1.4.6",
+		NextVersion:          "1.4.7",
+		Bump:                 "patch",
+		FloatingVersionMajor: "1",
+		FloatingVersionMinor: "1.4",
+		FloatingTagsMoved:    []string{"v1.4"},
+		FloatingTagsSkipped:  []string{"v1", "latest", "stable"},
+		TagsCreated:          []string{"v1.4", "v1.4.7"},
+		Commits:              commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldMoveLatestAndStableTagsToGreatestRelease(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	previous := semver.MustParse("2.3.0")
+	prerelease := semver.MustParse("3.0.0-rc.1")
+
+	mockScm := core.NewMockScm(ctrl)
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "feat: add new feature"},
+		{Hash: "c0", Tags: []*semver.Version{&previous}},
+	}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return([]*semver.Version{&previous, &prerelease}, nil)
+
+	mockScm.EXPECT().Tag(gomock.Any(), "latest", "c1", true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "stable", "c1", true).Return(nil).Times(1)
+	mockScm.EXPECT().Tag(gomock.Any(), "v2.4.0", "c1", false).Return(nil).Times(1)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddLatestTag: true, AddStableTag: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:        core.APIVersion,
+		Released:          true,
+		PreviousVersion:   "2.3.0",
+		NextVersion:       "2.4.0",
+		Bump:              "minor",
+		FloatingTagsMoved: []string{"latest", "stable"},
+		TagsCreated:       []string{"latest", "stable", "v2.4.0"},
+		Commits:           commitLogs[:1],
+	}, result)
+	assert.Nil(t, err)
+}
+
+func TestCalculateCommandImpl_ShouldFailIfTagsCannotBeListedForFloatingTags(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil)
+	mockScm.EXPECT().GetTags().Return(nil, errExpectedFromTest)
+	mockScm.EXPECT().Tag(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Empty(t, result)
+	assert.Equal(t, errExpectedFromTest, err)
+}
+
+func TestCalculateCommandImpl_ShouldReportPushedReferences(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	pushed := []core.PushResult{{Ref: "refs/tags/v0.1.0", Status: core.PushStatusPushed}}
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	commitLogs := []*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(nil)
+	mockScm.EXPECT().Push(gomock.Any()).Return(pushed, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:  core.APIVersion,
+		Released:    true,
+		NextVersion: "0.1.0",
+		Bump:        "minor",
+		TagsCreated: []string{"v0.1.0"},
+		Pushed:      pushed,
+		Commits:     commitLogs[:1],
+	}, result)
 	assert.Nil(t, err)
 }
+
+func TestCalculateCommandImpl_ShouldRollBackTagsIfPushFails(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	pushed := []core.PushResult{{Ref: "refs/tags/v0.1.0", Status: core.PushStatusRejected, Error: "rejected"}}
+	rolledBack := []core.RollbackResult{
+		{Ref: "refs/tags/v0.1.0", Status: core.RollbackStatusDeleted},
+		{Ref: "refs/tags/v0", Status: core.RollbackStatusRestored, Hash: "b0"},
+	}
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	commitLogs := []*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0", "c0", true).Return(nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1", "c0", true).Return(nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(nil)
+	mockScm.EXPECT().Push(gomock.Any()).Return(pushed, errExpectedFromTest)
+	mockScm.EXPECT().Rollback().Return(rolledBack, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddFloatingTags: true, Push: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.ErrorIs(t, err, errExpectedFromTest)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:           core.APIVersion,
+		Released:             true,
+		NextVersion:          "0.1.0",
+		Bump:                 "minor",
+		FloatingVersionMajor: "0",
+		FloatingVersionMinor: "0.1",
+		FloatingTagsMoved:    []string{"v0", "v0.1"},
+		Pushed:               pushed,
+		RolledBack:           rolledBack,
+		Commits:              commitLogs[:1],
+	}, result)
+}
+
+func TestCalculateCommandImpl_ShouldRollBackFloatingTagsIfVersionTagFails(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil)
+	mockScm.EXPECT().GetTags().Return(nil, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "latest", "c0", true).Return(nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(errExpectedFromTest)
+	mockScm.EXPECT().Push(gomock.Any()).Times(0)
+	mockScm.EXPECT().Rollback().Return([]core.RollbackResult{{Ref: "refs/tags/latest", Status: core.RollbackStatusDeleted}}, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, AddLatestTag: true, Push: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.ErrorIs(t, err, errExpectedFromTest)
+	assert.Equal(t, []core.RollbackResult{{Ref: "refs/tags/latest", Status: core.RollbackStatusDeleted}},
+		result.(core.CalculateOutput).RolledBack)
+}
+
+func TestCalculateCommandImpl_ShouldRetryWithNextVersionOnTagConflict(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	released := semver.MustParse("1.0.0")
+	concurrent := semver.MustParse("1.0.1")
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "fix: parallel fix"},
+		{Hash: "c0", Tags: []*semver.Version{&released}},
+	}
+
+	mockScm := core.NewMockScm(ctrl)
+	gomock.InOrder(
+		mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil),
+		mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil),
+		mockScm.EXPECT().Tag(gomock.Any(), "v1.0.1", "c1", false).Return(nil),
+		mockScm.EXPECT().Push(gomock.Any()).Return(nil, core.ErrTagConflict),
+		mockScm.EXPECT().Rollback().Return([]core.RollbackResult{{Ref: "refs/tags/v1.0.1", Status: core.RollbackStatusDeleted}}, nil),
+		mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil),
+		mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil),
+		mockScm.EXPECT().GetTags().Return([]*semver.Version{&released, &concurrent}, nil),
+		mockScm.EXPECT().Tag(gomock.Any(), "v1.0.2", "c1", false).Return(nil),
+		mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil),
+	)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:      core.APIVersion,
+		Released:        true,
+		PreviousVersion: "1.0.0",
+		NextVersion:     "1.0.2",
+		Bump:            "patch",
+		TagsCreated:     []string{"v1.0.2"},
+		Commits:         commitLogs[:1],
+	}, result)
+}
+
+func TestCalculateCommandImpl_ShouldNotRetryOnceARemoteAcceptedTheVersionTag(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	fetched := []core.TagChange{{Ref: "refs/tags/v0", Status: core.TagChangeAdded, Hash: "b0"}}
+	pushed := []core.PushResult{
+		{Remote: "origin", Ref: "refs/tags/v0.1.0", Status: core.PushStatusPushed},
+		{Remote: "mirror", Ref: "refs/tags/v0.1.0", Status: core.PushStatusRejected, Error: "tag conflict"},
+	}
+	rolledBack := []core.RollbackResult{{Ref: "refs/tags/v0.1.0", Status: core.RollbackStatusDeleted}}
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(fetched, nil).Times(1)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(nil)
+	mockScm.EXPECT().Push(gomock.Any()).Return(pushed, core.ErrTagConflict)
+	mockScm.EXPECT().Rollback().Return(rolledBack, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.ErrorIs(t, err, core.ErrTagConflict)
+	assert.Equal(t, fetched, result.Fetched)
+	assert.Equal(t, pushed, result.Pushed)
+	assert.Equal(t, rolledBack, result.RolledBack)
+}
+
+func TestCalculateCommandImpl_ShouldReportTheFetchOfTheLastAttempt(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	first := []core.TagChange{{Ref: "refs/tags/v0.1.0", Status: core.TagChangeAdded, Hash: "b0"}}
+	second := []core.TagChange{{Ref: "refs/tags/v0.1.1", Status: core.TagChangeAdded, Hash: "b1"}}
+	released := semver.MustParse("0.1.0")
+	concurrent := semver.MustParse("0.1.1")
+
+	mockScm := core.NewMockScm(ctrl)
+	gomock.InOrder(
+		mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(first, nil),
+		mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil),
+		mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(core.ErrTagConflict),
+		mockScm.EXPECT().Rollback().Return(nil, nil),
+		mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(second, nil),
+		mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil),
+		mockScm.EXPECT().GetTags().Return([]*semver.Version{&released, &concurrent}, nil),
+		mockScm.EXPECT().Tag(gomock.Any(), "v0.1.2", "c0", false).Return(nil),
+		mockScm.EXPECT().Push(gomock.Any()).Return(nil, nil),
+	)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.2", result.NextVersion)
+	assert.Equal(t, second, result.Fetched)
+}
+
+func TestCalculateCommandImpl_ShouldGiveUpAfterRepeatedTagConflicts(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil).Times(3)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil).Times(3)
+	mockScm.EXPECT().GetTags().Return(nil, nil).Times(2)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(core.ErrTagConflict).Times(3)
+	mockScm.EXPECT().Rollback().Return(nil, nil).Times(3)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	_, err := calculateCommand.Execute(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrTagConflict)
+}
+
+func TestCalculateCommand_ShouldPushReleaseTaggedByEarlierRun(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	gittest.CommitFile(t, source, "chore: initial commit")
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	head := gittest.CommitFile(t, clone, "feat: first feature")
+
+	result, err := core.NewCalculateCommandBuilder().SetPath(clonePath).BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v0.1.0"}, result.TagsCreated)
+	assert.Empty(t, result.Pushed)
+
+	result, err = core.NewCalculateCommandBuilder().SetPath(clonePath).SetPush(true).BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", result.NextVersion)
+	assert.Empty(t, result.TagsCreated)
+	assert.Equal(t, []core.PushResult{{Remote: "origin", Ref: "refs/tags/v0.1.0", Status: core.PushStatusPushed}}, result.Pushed)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	ref, err := remote.Tag("v0.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+
+	result, err = core.NewCalculateCommandBuilder().SetPath(clonePath).SetPush(true).BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.PushResult{{Remote: "origin", Ref: "refs/tags/v0.1.0", Status: core.PushStatusUpToDate}}, result.Pushed)
+
+	_, err = clone.Tag("v0.1.0")
+	assert.NoError(t, err, "an existing release tag is never rolled back")
+}
+
+func TestCalculateCommand_ShouldReleaseDistinctVersionsFromConcurrentPipelines(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+
+	pipelines := make([]string, 2)
+	for i := range pipelines {
+		pipelines[i] = filepath.Join(t.TempDir(), "pipeline")
+		clone, errClone := git.PlainClone(pipelines[i], false, &git.CloneOptions{URL: remotePath})
+		require.NoError(t, errClone)
+		gittest.CommitFile(t, clone, "fix: concurrent fix")
+	}
+
+	versions := make([]string, len(pipelines))
+	errs := make([]error, len(pipelines))
+
+	var wg sync.WaitGroup
+
+	for i, path := range pipelines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var result interface{}
+
+			result, errs[i] = core.NewCalculateCommandBuilder().SetPath(path).SetPush(true).Build().Execute(context.Background())
+			if output, ok := result.(core.CalculateOutput); ok {
+				versions[i] = output.NextVersion
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	assert.ElementsMatch(t, []string{"1.0.1", "1.0.2"}, versions)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	for i, path := range pipelines {
+		clone, errOpen := git.PlainOpen(path)
+		require.NoError(t, errOpen)
+
+		head, errHead := clone.Head()
+		require.NoError(t, errHead)
+
+		ref, errTag := remote.Tag("v" + versions[i])
+		require.NoError(t, errTag)
+		assert.Equal(t, head.Hash(), ref.Hash())
+	}
+}
+
+func TestCalculateCommandImpl_ShouldFetchTagsBeforeCalculating(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	released := semver.MustParse("1.0.0")
+	fetched := []core.TagChange{{Ref: "refs/tags/v1.0.0", Status: core.TagChangeAdded, Hash: "c0"}}
+
+	commitLogs := []*core.CommitLog{
+		{Hash: "c1", Message: "fix: a fix"},
+		{Hash: "c0", Tags: []*semver.Version{&released}},
+	}
+
+	mockScm := core.NewMockScm(ctrl)
+	gomock.InOrder(
+		mockScm.EXPECT().FetchTags(gomock.Any(), true).Return(fetched, nil),
+		mockScm.EXPECT().GetCommitLog(gomock.Any()).Return(commitLogs, nil),
+	)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Fetch: true, Prune: true, DisableTagging: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, core.CalculateOutput{
+		APIVersion:      core.APIVersion,
+		Released:        true,
+		PreviousVersion: "1.0.0",
+		NextVersion:     "1.0.1",
+		Bump:            "patch",
+		Fetched:         fetched,
+		Commits:         commitLogs[:1],
+	}, result)
+}
+
+func TestCalculateCommandImpl_ShouldFailIfTagsCannotBeFetched(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, errExpectedFromTest)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Times(0)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Fetch: true}
+
+	result, err := calculateCommand.Execute(context.Background())
+
+	assert.ErrorIs(t, err, errExpectedFromTest)
+	assert.Empty(t, result)
+}
+
+func TestCalculateCommandImpl_ShouldFailWithoutCommits(t *testing.T) {
+	t.Parallel()
+
+	path, _ := gittest.InitRepository(t)
+
+	calculateCommand := core.NewCalculateCommandBuilder().SetPath(path).SetDisableTagging(true).BuildCalculateCommand()
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrNoCommits)
+	assert.Nil(t, result)
+}
+
+func TestCalculateCommandImpl_ShouldNotTagDirtyTreeIfCleanTreeIsRequired(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil)
+	mockScm.EXPECT().IsClean(gomock.Any()).Return(false, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, RequireClean: true}
+
+	_, err := calculateCommand.Calculate(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrDirtyTree)
+}
+
+func TestCalculateCommandImpl_ShouldReturnCommitsSincePreviousVersion(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	fix := gittest.CommitFile(t, repo, "fix: a fix")
+	feature := gittest.CommitFile(t, repo, "feat: a feature")
+
+	calculateCommand := core.NewCalculateCommandBuilder().SetPath(path).SetRequireClean(true).BuildCalculateCommand()
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.PreviousVersion)
+	assert.Equal(t, "1.1.0", result.NextVersion)
+	assert.Equal(t, "minor", result.Bump)
+	assert.Equal(t, []string{"v1.1.0"}, result.TagsCreated)
+	require.Len(t, result.Commits, 2)
+	assert.Equal(t, feature.String(), result.Commits[0].Hash)
+	assert.Equal(t, fix.String(), result.Commits[1].Hash)
+}
+
+func TestCalculateCommandImpl_ShouldRollBackTagsIfCanceledWhilePushing(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	rolledBack := []core.RollbackResult{{Ref: "refs/tags/v0.1.0", Status: core.RollbackStatusDeleted}}
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil).Times(1)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{{Hash: "c0", Message: "feat: initial"}}, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v0.1.0", "c0", false).Return(nil)
+	mockScm.EXPECT().Push(gomock.Any()).Return(nil, context.Canceled)
+	mockScm.EXPECT().Rollback().Return(rolledBack, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{Scm: mockScm, Push: true}
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, rolledBack, result.RolledBack)
+	assert.Empty(t, result.TagsCreated)
+}
+
+func TestCalculateCommandImpl_ShouldNotFailIfHeadIsAlreadyReleased(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	head := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", head, nil)
+	require.NoError(t, err)
+
+	calculateCommand := core.NewCalculateCommandBuilder().SetPath(path).SetAddFloatingTags(true).BuildCalculateCommand()
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", result.NextVersion)
+	assert.Equal(t, []string{"v1", "v1.0"}, result.TagsCreated)
+	assert.Empty(t, result.RolledBack)
+}
+
+func TestCalculateCommandImpl_ShouldNotReleaseWithoutReleasingCommits(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().FetchTags(gomock.Any(), false).Return(nil, nil)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{
+		{Hash: "c2", Message: "test: more cases"},
+		{Hash: "c1", Message: "docs: usage"},
+		{Hash: "c0", Message: "feat: initial", Tags: []*semver.Version{{Major: 1}}},
+	}, nil)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:             mockScm,
+		Push:            true,
+		AddFloatingTags: true,
+		CommitTypes:     core.CommitTypes{"feat": core.MINOR, "fix": core.PATCH, "docs": core.NONE, "test": core.NONE},
+	}
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.Released)
+	assert.Equal(t, "1.0.0", result.PreviousVersion)
+	assert.Equal(t, "1.0.0", result.NextVersion)
+	assert.Equal(t, "none", result.Bump)
+	assert.Empty(t, result.TagsCreated)
+}
+
+func TestCalculateCommandImpl_ShouldNotReleaseChoreDocsOrTestCommitsByDefault(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "chore: update dependencies")
+	gittest.CommitFile(t, repo, "docs: usage")
+	gittest.CommitFile(t, repo, "test: more cases")
+
+	result, err := core.NewCalculateCommandBuilder().SetPath(path).BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.False(t, result.Released)
+	assert.Equal(t, "1.0.0", result.NextVersion)
+	assert.Equal(t, "none", result.Bump)
+	assert.Empty(t, result.TagsCreated)
+}
+
+func TestCalculateCommandImpl_ShouldAggregateBumpWithPatchReleasingTypes(t *testing.T) {
+	t.Parallel()
+
+	// The default types before the none component, which released a patch for every known type but feat.
+	patchTypes := core.CommitTypes{
+		"feat": core.MINOR, "fix": core.PATCH, "chore": core.PATCH, "docs": core.PATCH,
+		"style": core.PATCH, "refactor": core.PATCH, "perf": core.PATCH, "test": core.PATCH,
+	}
+
+	path, repo := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "docs: usage")
+
+	builder := core.NewCalculateCommandBuilder().SetPath(path).SetCommitTypes(patchTypes).SetDisableTagging(true)
+
+	result, err := builder.BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Released)
+	assert.Equal(t, "1.0.1", result.NextVersion)
+
+	gittest.CommitFile(t, repo, "feat: new option")
+	gittest.CommitFile(t, repo, "test: more cases")
+
+	result, err = builder.BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.NextVersion, "the feature bumps the minor version although HEAD is a test commit")
+	assert.Equal(t, "minor", result.Bump)
+	assert.Len(t, result.Commits, 3)
+}
+
+func TestCalculateCommandImpl_ShouldBumpByGreatestUpdateSincePreviousVersion(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockScm := core.NewMockScm(ctrl)
+	mockScm.EXPECT().GetCommitLog(gomock.Any()).Return([]*core.CommitLog{
+		{Hash: "c2", Message: "docs: usage"},
+		{Hash: "c1", Message: "feat: new option"},
+		{Hash: "c0", Message: "feat!: initial", Tags: []*semver.Version{{Major: 1}}},
+	}, nil)
+	mockScm.EXPECT().Tag(gomock.Any(), "v1.1.0", "c2", false).Return(nil)
+
+	calculateCommand := &core.CalculateCommandImpl{
+		Scm:         mockScm,
+		CommitTypes: core.CommitTypes{"feat": core.MINOR, "docs": core.NONE},
+	}
+
+	result, err := calculateCommand.Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.True(t, result.Released)
+	assert.Equal(t, "1.1.0", result.NextVersion)
+	assert.Equal(t, "minor", result.Bump)
+}
+
+func TestCalculateCommandImpl_ShouldNotReleaseRevertedFeature(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+	feature := gittest.CommitFile(t, repo, "feat(api): add X")
+	gittest.CommitFile(t, repo, "Revert \"feat(api): add X\"\n\nThis reverts commit "+feature.String()+".\n")
+
+	result, err := core.NewCalculateCommandBuilder().SetPath(path).BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.1", result.NextVersion)
+	assert.Equal(t, "patch", result.Bump)
+	assert.Len(t, result.Commits, 3)
+}
+
+func TestCalculateCommandImpl_ShouldUpdateFilesAndChangelogOfRelease(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	feature := gittest.CommitFile(t, repo, "feat(api): add X")
+
+	files := []core.FileUpdater{{Path: "VERSION"}}
+	changelog, err := core.NewChangelog("", nil)
+	require.NoError(t, err)
+
+	subdirectory := filepath.Join(path, "services")
+	require.NoError(t, os.Mkdir(subdirectory, 0o700))
+
+	builder := core.NewCalculateCommandBuilder().SetPath(subdirectory).SetFiles(files).SetChangelog(&changelog)
+
+	result, err := builder.BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.NextVersion)
+	assert.Equal(t, []string{"VERSION", "CHANGELOG.md"}, result.FilesUpdated)
+
+	version, err := os.ReadFile(filepath.Join(path, "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0\n", string(version))
+
+	content, err := os.ReadFile(filepath.Join(path, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "## v1.1.0 (")
+	assert.Contains(t, string(content), "- **api:** add X ("+feature.String()[:7]+")")
+
+	result, err = builder.BuildCalculateCommand().Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VERSION"}, result.FilesUpdated, "the changelog already has the release")
+
+	_, err = core.NewCalculateCommandBuilder().
+		SetRepositoryURL(path).
+		SetFiles(files).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrInvalidConfig)
+}
+
+func TestCalculateCommandImpl_ShouldNotUpdateFilesWithoutTagging(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	gittest.CommitFile(t, repo, "feat: initial commit")
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetDisableTagging(true).
+		SetFiles([]core.FileUpdater{{Path: "VERSION"}}).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.FilesUpdated)
+	assert.NoFileExists(t, filepath.Join(path, "VERSION"))
+}
@@ -0,0 +1,164 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultChangelogFile is the path of the changelog when none is configured.
+	DefaultChangelogFile = "CHANGELOG.md"
+	changelogTitle       = "# Changelog\n\n"
+	changelogDateLayout  = "2006-01-02"
+	breakingChangesTitle = "Breaking Changes"
+	changelogPermissions = 0o644
+)
+
+const (
+	subjectTypeGroup = iota + 1
+	subjectBreakingGroup
+	subjectScopeGroup
+	subjectDescriptionGroup
+)
+
+// ErrInvalidChangelog is returned when the path or the sections of the changelog cannot be used.
+var ErrInvalidChangelog = errors.New("invalid changelog")
+
+// changelogSubjectPattern matches the type, the optional !, the optional scope and the description of the subject
+// of a conventional commit.
+var changelogSubjectPattern = regexp.MustCompile(`^([\w-]+)(!?)(?:\(([^)]*)\))?: (.+)$`)
+
+// Changelog adds the commits of each release to a markdown file, newest release first.
+type Changelog struct {
+	Path     string             // Path of the file relative to the repository root.
+	Sections []ChangelogSection // Sections of a release in order, breaking changes always come first.
+}
+
+// ChangelogSection lists the commits of a conventional commit type under a heading.
+type ChangelogSection struct {
+	Type  string
+	Title string
+}
+
+// DefaultChangelogSections returns the sections of the changelog when none are configured.
+func DefaultChangelogSections() []ChangelogSection {
+	return []ChangelogSection{
+		{Type: "feat", Title: "Features"},
+		{Type: "fix", Title: "Bug Fixes"},
+		{Type: "perf", Title: "Performance Improvements"},
+	}
+}
+
+// NewChangelog returns the changelog at the path relative to the repository root, CHANGELOG.md when empty,
+// with the sections, the default sections when empty. It returns ErrInvalidChangelog when the path leaves
+// the repository or a section has no type or title.
+func NewChangelog(path string, sections []ChangelogSection) (Changelog, error) {
+	if path == "" {
+		path = DefaultChangelogFile
+	}
+
+	if !filepath.IsLocal(path) {
+		return Changelog{}, fmt.Errorf("%w: %q is not a path inside the repository", ErrInvalidChangelog, path)
+	}
+
+	if len(sections) == 0 {
+		sections = DefaultChangelogSections()
+	}
+
+	for _, section := range sections {
+		if section.Type == "" || section.Title == "" {
+			return Changelog{}, fmt.Errorf("%w: section %q of type %q needs a type and a title",
+				ErrInvalidChangelog, section.Title, section.Type)
+		}
+	}
+
+	return Changelog{Path: filepath.Clean(path), Sections: sections}, nil
+}
+
+// Update adds the release of the tag to the changelog in the repository at root, below the title of the file,
+// with the commits of the release, newest first. The file is created when it does not exist. It returns false
+// without changing the file when the changelog already has the release.
+func (c Changelog) Update(root, tagName string, date time.Time, commitLogs []*CommitLog) (bool, error) {
+	file := filepath.Join(root, c.Path)
+
+	content, err := os.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	heading := "## " + tagName
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == heading || strings.HasPrefix(line, heading+" ") {
+			return false, nil
+		}
+	}
+
+	title, rest := changelogTitle, string(content)
+	if strings.HasPrefix(rest, "# ") {
+		line, after, _ := strings.Cut(rest, "\n")
+		title, rest = line+"\n\n", strings.TrimLeft(after, "\n")
+	}
+
+	release := c.render(heading+" ("+date.UTC().Format(changelogDateLayout)+")", commitLogs)
+	if rest != "" {
+		release += "\n"
+	}
+
+	err = os.WriteFile(file, []byte(title+release+rest), changelogPermissions)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// render returns the markdown of a release with the heading, listing the breaking changes and then the commits
+// of the types of the sections. Commits of other types are left out.
+func (c Changelog) render(heading string, commitLogs []*CommitLog) string {
+	entries := map[string][]string{}
+
+	for _, commit := range commitLogs {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+
+		match := changelogSubjectPattern.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+
+		description := match[subjectDescriptionGroup]
+		if scope := match[subjectScopeGroup]; scope != "" {
+			description = "**" + scope + ":** " + description
+		}
+
+		entry := "- " + description + " (" + shortHash(commit.Hash) + ")"
+
+		if match[subjectBreakingGroup] == "!" || strings.Contains(commit.Message, breakingChangeType) {
+			entries[breakingChangesTitle] = append(entries[breakingChangesTitle], entry)
+		} else {
+			entries[match[subjectTypeGroup]] = append(entries[match[subjectTypeGroup]], entry)
+		}
+	}
+
+	var release strings.Builder
+
+	release.WriteString(heading + "\n")
+
+	writeSection := func(title string, lines []string) {
+		if len(lines) > 0 {
+			release.WriteString("\n### " + title + "\n\n" + strings.Join(lines, "\n") + "\n")
+		}
+	}
+
+	writeSection(breakingChangesTitle, entries[breakingChangesTitle])
+
+	for _, section := range c.Sections {
+		writeSection(section.Title, entries[section.Type])
+	}
+
+	return release.String()
+}
@@ -0,0 +1,94 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangelog_ShouldAddReleaseBelowTitle(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	file := filepath.Join(root, "CHANGELOG.md")
+	require.NoError(t, os.WriteFile(file, []byte("# Release notes\n\n## v1.0.0 (2026-01-05)\n"), 0o600))
+
+	changelog, err := core.NewChangelog("", nil)
+	require.NoError(t, err)
+
+	commits := []*core.CommitLog{
+		{Hash: "3333333333", Message: "docs: describe the API"},
+		{Hash: "2222222222", Message: "fix(api): handle empty bodies\n\nDetails."},
+		{Hash: "1111111111", Message: "feat!: drop the v1 API"},
+		{Hash: "0000000000", Message: "feat: add the v2 API"},
+	}
+	date := time.Date(2026, 2, 3, 10, 0, 0, 0, time.UTC)
+
+	updated, err := changelog.Update(root, "v2.0.0", date, commits)
+
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, `# Release notes
+
+## v2.0.0 (2026-02-03)
+
+### Breaking Changes
+
+- drop the v1 API (1111111)
+
+### Features
+
+- add the v2 API (0000000)
+
+### Bug Fixes
+
+- **api:** handle empty bodies (2222222)
+
+## v1.0.0 (2026-01-05)
+`, string(content))
+
+	updated, err = changelog.Update(root, "v2.0.0", date, commits)
+
+	require.NoError(t, err)
+	assert.False(t, updated, "a release already in the changelog must not be added again")
+}
+
+func TestChangelog_ShouldCreateFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	changelog, err := core.NewChangelog("docs/CHANGES.md", []core.ChangelogSection{{Type: "docs", Title: "Documentation"}})
+	require.NoError(t, err)
+	require.NoError(t, os.Mkdir(filepath.Join(root, "docs"), 0o700))
+
+	updated, err := changelog.Update(root, "v1.0.1", time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC),
+		[]*core.CommitLog{{Hash: "3333333333", Message: "docs: describe the API"}, {Hash: "1111111111", Message: "fix: a fix"}})
+
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	content, err := os.ReadFile(filepath.Join(root, "docs", "CHANGES.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Changelog\n\n## v1.0.1 (2026-02-03)\n\n### Documentation\n\n- describe the API (3333333)\n", string(content))
+}
+
+func TestNewChangelog_ShouldRejectInvalidChangelogs(t *testing.T) {
+	t.Parallel()
+
+	_, err := core.NewChangelog("../CHANGELOG.md", nil)
+
+	assert.ErrorIs(t, err, core.ErrInvalidChangelog)
+
+	_, err = core.NewChangelog("", []core.ChangelogSection{{Type: "feat"}})
+
+	assert.ErrorIs(t, err, core.ErrInvalidChangelog)
+}
@@ -1,7 +1,28 @@
 package core
 
+import (
+	"context"
+
+	"github.com/martoc/semver/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// APIVersion is the version of the JSON output of the commands. It is only incremented by changes that
+// break consumers, such as removing or renaming a field; new fields are added within the same version.
+const APIVersion = "semver/v1"
+
 // Command is an interface that represents a command.
 // It defines the Execute method, which executes the command and returns a string and an error.
+// The command stops when the context is canceled.
 type Command interface {
-	Execute() (interface{}, error)
+	Execute(ctx context.Context) (interface{}, error)
+}
+
+// orDefaultLogger returns the logger, or the shared logger of the command line when it is nil.
+func orDefaultLogger(log logrus.FieldLogger) logrus.FieldLogger {
+	if log == nil {
+		return logger.GetInstance()
+	}
+
+	return log
 }
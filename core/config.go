@@ -0,0 +1,665 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configEnvironmentPrefix = "SEMVER_"
+	configListSeparator     = ","
+	configRulesSeparator    = ";"
+	configKeyValues         = 2
+	tomlConfigExtension     = ".toml"
+)
+
+// ErrInvalidConfig is returned when the configuration file or a SEMVER_* variable cannot be parsed.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ConfigFileNames lists the names of the configuration file, in the order they are looked up in a directory.
+var ConfigFileNames = []string{".semver.yaml", ".semver.yml", ".semver.toml"}
+
+// Config holds the settings of the calculate command that can be set in the configuration file.
+// The keys are the names of the corresponding flags.
+type Config struct {
+	Push            bool                    `yaml:"push"`
+	Fetch           bool                    `yaml:"fetch"`
+	Prune           bool                    `yaml:"prune"`
+	Deepen          bool                    `yaml:"deepen"`
+	FirstParent     bool                    `yaml:"first-parent"`
+	MergeAware      bool                    `yaml:"merge-aware"`
+	DisableTagging  bool                    `yaml:"disable-tagging"`
+	RequireClean    bool                    `yaml:"require-clean"`
+	AddFloatingTags bool                    `yaml:"add-floating-tags"`
+	AddLatestTag    bool                    `yaml:"add-latest-tag"`
+	AddStableTag    bool                    `yaml:"add-stable-tag"`
+	TolerantTags    bool                    `yaml:"tolerant-tags"`
+	Remotes         []string                `yaml:"remotes,omitempty"`
+	Line            string                  `yaml:"line,omitempty"`
+	BaseTag         string                  `yaml:"base-tag,omitempty"`     // Base tag policy, greatest-reachable when empty.
+	TagPatterns     []string                `yaml:"tag-patterns,omitempty"` // Patterns capturing the version of legacy tags.
+	TagInclude      []string                `yaml:"tag-include,omitempty"`  // Globs or /regular expressions/ of release tags.
+	TagExclude      []string                `yaml:"tag-exclude,omitempty"`  // Globs or /regular expressions/ of other tags.
+	TagTemplate     string                  `yaml:"tag-template,omitempty"` // Tag template of the default stream, v{version} when empty.
+	Stream          string                  `yaml:"stream,omitempty"`       // Stream released, the default stream when empty.
+	Streams         map[string]StreamConfig `yaml:"streams,omitempty"`      // Tag templates of the streams by name.
+	BranchRules     []BranchRuleConfig      `yaml:"branch-rules,omitempty"`
+	Types           map[string]string       `yaml:"types,omitempty"`     // Version component incremented per commit type.
+	Files           []FileConfig            `yaml:"files,omitempty"`     // Files the released version is written to.
+	Changelog       *ChangelogConfig        `yaml:"changelog,omitempty"` // Changelog the released commits are added to.
+}
+
+// BranchRuleConfig is a branch rule in the configuration file. It is either a mapping with the pattern
+// and the options of the rule, or a string in the format of the --branch-rule flag.
+type BranchRuleConfig struct {
+	Pattern    string `yaml:"pattern"`
+	Prerelease string `yaml:"prerelease,omitempty"`
+	MaxBump    string `yaml:"max-bump,omitempty"`
+	Line       string `yaml:"line,omitempty"`
+	Tag        *bool  `yaml:"tag,omitempty"`
+	Push       *bool  `yaml:"push,omitempty"`
+}
+
+// DefaultConfig returns the configuration used when no file, variable or flag sets a setting.
+func DefaultConfig() Config {
+	types := map[string]string{}
+	for commitType, component := range DefaultCommitTypes() {
+		types[commitType] = component.String()
+	}
+
+	return Config{Types: types}
+}
+
+// FindConfigFile looks for a configuration file in the directory of the path and then in every parent
+// directory, through the root of the repository and up to the root of the file system, so that a file at the
+// root of a repository applies to all of its subdirectories and a file in a parent directory applies to all
+// of its repositories. The nearest file wins, and within a directory the names are looked up in the order
+// of ConfigFileNames. It returns an empty string when no configuration file exists.
+func FindConfigFile(path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info, errStat := os.Stat(dir); errStat == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		for _, name := range ConfigFileNames {
+			file := filepath.Join(dir, name)
+			if info, errStat := os.Stat(file); errStat == nil && !info.IsDir() {
+				return file, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+
+		dir = parent
+	}
+}
+
+// LoadConfigFile reads the configuration file over the settings of the config, keeping the settings
+// the file does not have. Commit types are merged with the existing ones. A file with the .toml extension
+// is TOML and any other file is YAML, both with the same keys.
+// It returns ErrInvalidConfig when the file has unknown keys or values of the wrong type.
+func LoadConfigFile(file string, config *Config) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(file), tomlConfigExtension) {
+		content, err = tomlToYAML(content)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, file, err)
+		}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, file, err)
+	}
+
+	return nil
+}
+
+// tomlToYAML converts a TOML document to YAML, so that both formats are decoded and checked alike.
+func tomlToYAML(content []byte) ([]byte, error) {
+	document := map[string]any{}
+
+	_, err := toml.Decode(string(content), &document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(document) == 0 {
+		return nil, nil
+	}
+
+	return yaml.Marshal(document)
+}
+
+// Bools returns the boolean settings by key, so that they can be set from flags of the same name.
+func (c *Config) Bools() map[string]*bool {
+	return map[string]*bool{
+		"push":              &c.Push,
+		"fetch":             &c.Fetch,
+		"prune":             &c.Prune,
+		"deepen":            &c.Deepen,
+		"first-parent":      &c.FirstParent,
+		"merge-aware":       &c.MergeAware,
+		"disable-tagging":   &c.DisableTagging,
+		"require-clean":     &c.RequireClean,
+		"add-floating-tags": &c.AddFloatingTags,
+		"add-latest-tag":    &c.AddLatestTag,
+		"add-stable-tag":    &c.AddStableTag,
+		"tolerant-tags":     &c.TolerantTags,
+	}
+}
+
+// ConfigEnvironmentVariable returns the name of the variable overriding the setting with the key,
+// for example SEMVER_ADD_FLOATING_TAGS for add-floating-tags.
+func ConfigEnvironmentVariable(key string) string {
+	return configEnvironmentPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// ApplyEnvironment overrides the settings with the SEMVER_* variables that are set. It takes a lookup
+// function such as os.Getenv. Remotes are separated by commas, branch rules by semicolons in the format
+// of the --branch-rule flag, tag patterns and tag filters by semicolons, and types are type=component pairs
+// separated by commas, merged with the existing types. It returns ErrInvalidConfig when a variable cannot be parsed.
+func (c *Config) ApplyEnvironment(getenv func(string) string) error {
+	for key, setting := range c.Bools() {
+		name := ConfigEnvironmentVariable(key)
+		if value := getenv(name); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, name, err)
+			}
+
+			*setting = parsed
+		}
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("remotes")); value != "" {
+		c.Remotes = splitConfigList(value, configListSeparator)
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("line")); value != "" {
+		c.Line = value
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("base-tag")); value != "" {
+		c.BaseTag = value
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("tag-patterns")); value != "" {
+		c.TagPatterns = splitConfigList(value, configRulesSeparator)
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("tag-include")); value != "" {
+		c.TagInclude = splitConfigList(value, configRulesSeparator)
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("tag-exclude")); value != "" {
+		c.TagExclude = splitConfigList(value, configRulesSeparator)
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("tag-template")); value != "" {
+		c.TagTemplate = value
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("stream")); value != "" {
+		c.Stream = value
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("branch-rules")); value != "" {
+		rules, err := ParseBranchRuleConfigs(splitConfigList(value, configRulesSeparator))
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, ConfigEnvironmentVariable("branch-rules"), err)
+		}
+
+		c.BranchRules = rules
+	}
+
+	if value := getenv(ConfigEnvironmentVariable("types")); value != "" {
+		return c.applyTypes(value)
+	}
+
+	return nil
+}
+
+// applyTypes merges type=component pairs separated by commas into the types.
+func (c *Config) applyTypes(value string) error {
+	if c.Types == nil {
+		c.Types = map[string]string{}
+	}
+
+	for _, pair := range splitConfigList(value, configListSeparator) {
+		keyValue := strings.SplitN(pair, "=", configKeyValues)
+		if len(keyValue) != configKeyValues {
+			return fmt.Errorf("%w: %s: %q is not type=component", ErrInvalidConfig, ConfigEnvironmentVariable("types"), pair)
+		}
+
+		c.Types[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+
+	return nil
+}
+
+// Validate parses every setting of the configuration and returns ErrInvalidConfig with the first invalid one.
+func (c *Config) Validate() error {
+	_, err := c.CommitTypes()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.VersionLine()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.BaseTagPolicy()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.TagParser()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.TagFilter()
+	if err != nil {
+		return err
+	}
+
+	for name, stream := range c.Streams {
+		_, err = NewStream(name, stream.Tag)
+		if err != nil {
+			return fmt.Errorf("%w: streams: %w", ErrInvalidConfig, err)
+		}
+	}
+
+	_, err = c.ReleaseStream()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ParsedBranchRules()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.FileUpdaters()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.ParsedChangelog()
+
+	return err
+}
+
+// CommitTypes returns the parsed commit types, or nil for the default commit types when there are none.
+func (c *Config) CommitTypes() (CommitTypes, error) {
+	if len(c.Types) == 0 {
+		return nil, nil //nolint:nilnil // A nil mapping selects the default commit types
+	}
+
+	commitTypes := CommitTypes{}
+
+	for commitType, name := range c.Types {
+		component, err := ParseSemanticVersionComponent(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: types: %s: %w", ErrInvalidConfig, commitType, err)
+		}
+
+		commitTypes[commitType] = component
+	}
+
+	return commitTypes, nil
+}
+
+// History returns the history mode of the settings. Merge aware takes precedence over first parent,
+// as it also walks the first parents.
+func (c *Config) History() HistoryMode {
+	switch {
+	case c.MergeAware:
+		return HistoryMergeAware
+	case c.FirstParent:
+		return HistoryFirstParent
+	default:
+		return HistoryAll
+	}
+}
+
+// BaseTagPolicy returns the parsed base tag policy.
+func (c *Config) BaseTagPolicy() (BaseTagPolicy, error) {
+	policy, err := ParseBaseTagPolicy(c.BaseTag)
+	if err != nil {
+		return policy, fmt.Errorf("%w: base-tag: %w", ErrInvalidConfig, err)
+	}
+
+	return policy, nil
+}
+
+// TagParser returns the parser of the tag names, with the compiled tag patterns and the declared streams,
+// sorted by name, whose tags are not releases of the other streams.
+func (c *Config) TagParser() (TagParser, error) {
+	parser, err := NewTagParser(c.TolerantTags, c.TagPatterns)
+	if err != nil {
+		return parser, fmt.Errorf("%w: tag-patterns: %w", ErrInvalidConfig, err)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(c.Streams)) {
+		stream, errStream := NewStream(name, c.Streams[name].Tag)
+		if errStream != nil {
+			return parser, fmt.Errorf("%w: streams: %w", ErrInvalidConfig, errStream)
+		}
+
+		parser.Streams = append(parser.Streams, stream)
+	}
+
+	return parser, nil
+}
+
+// TagFilter returns the parsed tag filter.
+func (c *Config) TagFilter() (TagFilter, error) {
+	filter, err := ParseTagFilter(c.TagInclude, c.TagExclude)
+	if err != nil {
+		return filter, fmt.Errorf("%w: tag filters: %w", ErrInvalidConfig, err)
+	}
+
+	return filter, nil
+}
+
+// ReleaseStream returns the stream released, with the tag template of the streams when it is declared.
+// The default stream has the tag template of the configuration.
+func (c *Config) ReleaseStream() (Stream, error) {
+	template := c.Streams[c.Stream].Tag
+	if c.Stream == "" {
+		template = c.TagTemplate
+	}
+
+	stream, err := NewStream(c.Stream, template)
+	if err != nil {
+		return stream, fmt.Errorf("%w: stream: %w", ErrInvalidConfig, err)
+	}
+
+	return stream, nil
+}
+
+// VersionLine returns the parsed version line.
+func (c *Config) VersionLine() (VersionLine, error) {
+	line, err := ParseVersionLine(c.Line)
+	if err != nil {
+		return "", fmt.Errorf("%w: line: %w", ErrInvalidConfig, err)
+	}
+
+	return line, nil
+}
+
+// ParsedBranchRules returns the parsed branch rules.
+func (c *Config) ParsedBranchRules() ([]BranchRule, error) {
+	rules := make([]BranchRule, 0, len(c.BranchRules))
+
+	for _, ruleConfig := range c.BranchRules {
+		rule, err := ruleConfig.BranchRule()
+		if err != nil {
+			return nil, fmt.Errorf("%w: branch-rules: %w", ErrInvalidConfig, err)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// FileUpdaters returns the parsed file updaters.
+func (c *Config) FileUpdaters() ([]FileUpdater, error) {
+	updaters := make([]FileUpdater, 0, len(c.Files))
+
+	for _, file := range c.Files {
+		updater, err := NewFileUpdater(file.Path, file.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: files: %w", ErrInvalidConfig, err)
+		}
+
+		updaters = append(updaters, updater)
+	}
+
+	return updaters, nil
+}
+
+// ParsedChangelog returns the parsed changelog, or nil when there is none.
+func (c *Config) ParsedChangelog() (*Changelog, error) {
+	if c.Changelog == nil {
+		return nil, nil //nolint:nilnil // A nil changelog disables the changelog
+	}
+
+	sections := make([]ChangelogSection, 0, len(c.Changelog.Sections))
+	for _, section := range c.Changelog.Sections {
+		sections = append(sections, ChangelogSection(section))
+	}
+
+	changelog, err := NewChangelog(c.Changelog.File, sections)
+	if err != nil {
+		return nil, fmt.Errorf("%w: changelog: %w", ErrInvalidConfig, err)
+	}
+
+	return &changelog, nil
+}
+
+// Apply sets the settings of the configuration on the builder.
+// It returns ErrInvalidConfig when a setting cannot be parsed.
+func (c *Config) Apply(builder *CalculateCommandBuilder) error {
+	commitTypes, err := c.CommitTypes()
+	if err != nil {
+		return err
+	}
+
+	line, err := c.VersionLine()
+	if err != nil {
+		return err
+	}
+
+	branchRules, err := c.ParsedBranchRules()
+	if err != nil {
+		return err
+	}
+
+	baseTag, err := c.BaseTagPolicy()
+	if err != nil {
+		return err
+	}
+
+	tagParser, err := c.TagParser()
+	if err != nil {
+		return err
+	}
+
+	tagFilter, err := c.TagFilter()
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.ReleaseStream()
+	if err != nil {
+		return err
+	}
+
+	files, err := c.FileUpdaters()
+	if err != nil {
+		return err
+	}
+
+	changelog, err := c.ParsedChangelog()
+	if err != nil {
+		return err
+	}
+
+	builder.
+		SetPush(c.Push).
+		SetFetch(c.Fetch).
+		SetPrune(c.Prune).
+		SetDeepen(c.Deepen).
+		SetHistory(c.History()).
+		SetDisableTagging(c.DisableTagging).
+		SetRequireClean(c.RequireClean).
+		SetAddFloatingTags(c.AddFloatingTags).
+		SetAddLatestTag(c.AddLatestTag).
+		SetAddStableTag(c.AddStableTag).
+		SetRemotes(c.Remotes).
+		SetLine(line).
+		SetBaseTag(baseTag).
+		SetTagParser(tagParser).
+		SetTagFilter(tagFilter).
+		SetStream(stream).
+		SetBranchRules(branchRules).
+		SetCommitTypes(commitTypes).
+		SetFiles(files).
+		SetChangelog(changelog)
+
+	return nil
+}
+
+// StreamConfig is a stream in the configuration file.
+type StreamConfig struct {
+	Tag string `yaml:"tag"` // Tag template with a {version} placeholder, <name>-v{version} when empty.
+}
+
+// FileConfig is a file the released version is written to in the configuration file.
+type FileConfig struct {
+	Path    string `yaml:"path"`              // Path of the file relative to the repository root.
+	Pattern string `yaml:"pattern,omitempty"` // Regular expression whose first group is the version, the whole file when empty.
+}
+
+// ChangelogConfig is the changelog in the configuration file.
+type ChangelogConfig struct {
+	File     string                   `yaml:"file,omitempty"`     // Path relative to the repository root, CHANGELOG.md when empty.
+	Sections []ChangelogSectionConfig `yaml:"sections,omitempty"` // Sections in order, the default sections when empty.
+}
+
+// ChangelogSectionConfig is a section of the changelog in the configuration file.
+type ChangelogSectionConfig struct {
+	Type  string `yaml:"type"`  // Conventional commit type listed in the section.
+	Title string `yaml:"title"` // Heading of the section.
+}
+
+// ParseBranchRuleConfigs parses branch rules in the format of the --branch-rule flag.
+func ParseBranchRuleConfigs(values []string) ([]BranchRuleConfig, error) {
+	rules := make([]BranchRuleConfig, 0, len(values))
+
+	for _, value := range values {
+		rule, err := ParseBranchRule(value)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, newBranchRuleConfig(rule))
+	}
+
+	return rules, nil
+}
+
+// newBranchRuleConfig returns the configuration of a parsed branch rule.
+func newBranchRuleConfig(rule BranchRule) BranchRuleConfig {
+	ruleConfig := BranchRuleConfig{Pattern: rule.Pattern, Prerelease: rule.Prerelease, Line: string(rule.Line)}
+
+	if rule.MaxBump != MAJOR {
+		ruleConfig.MaxBump = rule.MaxBump.String()
+	}
+
+	if rule.DisableTagging {
+		ruleConfig.Tag = new(bool)
+	}
+
+	if rule.DisablePush {
+		ruleConfig.Push = new(bool)
+	}
+
+	return ruleConfig
+}
+
+// UnmarshalYAML decodes a branch rule from a mapping or from a string in the format of the --branch-rule flag.
+func (r *BranchRuleConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		rule, err := ParseBranchRule(node.Value)
+		if err != nil {
+			return err
+		}
+
+		*r = newBranchRuleConfig(rule)
+
+		return nil
+	}
+
+	type plain BranchRuleConfig // Decodes the mapping without calling UnmarshalYAML again
+
+	return node.Decode((*plain)(r))
+}
+
+// BranchRule returns the parsed branch rule.
+func (r BranchRuleConfig) BranchRule() (BranchRule, error) {
+	options := map[string]string{"prerelease": r.Prerelease, "max-bump": r.MaxBump, "line": r.Line}
+	if r.Tag != nil {
+		options["tag"] = strconv.FormatBool(*r.Tag)
+	}
+
+	if r.Push != nil {
+		options["push"] = strconv.FormatBool(*r.Push)
+	}
+
+	rule := BranchRule{Pattern: r.Pattern}
+	if rule.Pattern == "" {
+		return rule, fmt.Errorf("%w: rule has no branch pattern", ErrInvalidBranchRule)
+	}
+
+	if _, err := path.Match(rule.Pattern, ""); err != nil {
+		return rule, fmt.Errorf("%w: %q: %w", ErrInvalidBranchRule, r.Pattern, err)
+	}
+
+	for key, value := range options {
+		if value == "" {
+			continue
+		}
+
+		if err := rule.set(key, value); err != nil {
+			return rule, fmt.Errorf("%w: %q: %w", ErrInvalidBranchRule, r.Pattern, err)
+		}
+	}
+
+	return rule, nil
+}
+
+// splitConfigList splits a list setting, trimming spaces and dropping empty items.
+func splitConfigList(value, separator string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(value, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,311 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConfigFile_ShouldSearchParentDirectories(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	nested := filepath.Join(root, "services", "api")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".semver.yml"), []byte("push: true\n"), 0o600))
+
+	file, err := core.FindConfigFile(nested)
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, ".semver.yml"), file)
+}
+
+func TestFindConfigFile_ShouldSearchAboveRepositoryRoot(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(parent, ".semver.toml"), []byte("push = true\n"), 0o600))
+
+	root := filepath.Join(parent, "repository")
+	nested := filepath.Join(root, "services")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git"), []byte("gitdir: ../main/.git/worktrees/repository\n"), 0o600))
+
+	file, err := core.FindConfigFile(nested)
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(parent, ".semver.toml"), file, "a file above the repository applies to it")
+
+	file, err = core.FindConfigFile(filepath.Join(parent, "not-a-repository"))
+
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(parent, ".semver.toml"), file, "a file above a directory outside of a repository applies")
+}
+
+func TestFindConfigFile_ShouldPreferNearestFileAndNameOrder(t *testing.T) {
+	t.Parallel()
+
+	parent := t.TempDir()
+	root := filepath.Join(parent, "repository")
+	nested := filepath.Join(root, "services")
+	require.NoError(t, os.MkdirAll(nested, 0o700))
+	require.NoError(t, os.Mkdir(filepath.Join(root, ".git"), 0o700))
+
+	for _, name := range []string{".semver.toml", ".semver.yml", ".semver.yaml"} {
+		require.NoError(t, os.WriteFile(filepath.Join(parent, name), []byte{}, 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(root, name), []byte{}, 0o600))
+
+		file, err := core.FindConfigFile(nested)
+
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(root, name), file, "the nearest directory wins, then the first of ConfigFileNames")
+	}
+}
+
+func TestLoadConfigFile_ShouldOverrideDefaults(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), ".semver.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+add-floating-tags: true
+remotes: [origin, mirror]
+branch-rules:
+  - develop:prerelease=beta
+  - pattern: release/*
+    max-bump: minor
+    push: false
+types:
+  docs: none
+  build: patch
+tag-template: release-{version}
+files:
+  - path: VERSION
+  - path: chart/Chart.yaml
+    pattern: '(?m)^appVersion: (.+)$'
+changelog:
+  sections:
+    - type: feat
+      title: New Features
+`), 0o600))
+
+	config := core.DefaultConfig()
+
+	require.NoError(t, core.LoadConfigFile(file, &config))
+	require.NoError(t, config.Validate())
+
+	assert.True(t, config.AddFloatingTags)
+	assert.False(t, config.Push)
+	assert.Equal(t, []string{"origin", "mirror"}, config.Remotes)
+	assert.Equal(t, "none", config.Types["docs"])
+	assert.Equal(t, "patch", config.Types["build"])
+	assert.Equal(t, "minor", config.Types["feat"])
+
+	commitTypes, err := config.CommitTypes()
+	require.NoError(t, err)
+	assert.Equal(t, core.NONE, commitTypes["docs"])
+
+	rules, err := config.ParsedBranchRules()
+	require.NoError(t, err)
+	assert.Equal(t, []core.BranchRule{
+		{Pattern: "develop", Prerelease: "beta"},
+		{Pattern: "release/*", MaxBump: core.MINOR, DisablePush: true},
+	}, rules)
+
+	stream, err := config.ReleaseStream()
+	require.NoError(t, err)
+	assert.Equal(t, "release-1.2.3", stream.TagName("1.2.3"))
+
+	files, err := config.FileUpdaters()
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Nil(t, files[0].Pattern)
+	assert.Equal(t, filepath.Join("chart", "Chart.yaml"), files[1].Path)
+
+	changelog, err := config.ParsedChangelog()
+	require.NoError(t, err)
+	assert.Equal(t, &core.Changelog{
+		Path:     core.DefaultChangelogFile,
+		Sections: []core.ChangelogSection{{Type: "feat", Title: "New Features"}},
+	}, changelog)
+}
+
+func TestLoadConfigFile_ShouldReadToml(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), ".semver.toml")
+	require.NoError(t, os.WriteFile(file, []byte(`
+add-floating-tags = true
+remotes = ["origin", "mirror"]
+tag-template = "release-{version}"
+branch-rules = [
+  "develop:prerelease=beta",
+  { pattern = "release/*", max-bump = "minor", push = false },
+]
+
+[types]
+docs = "none"
+
+[[files]]
+path = "VERSION"
+
+[changelog]
+sections = [{ type = "feat", title = "New Features" }]
+`), 0o600))
+
+	config := core.DefaultConfig()
+
+	require.NoError(t, core.LoadConfigFile(file, &config))
+	require.NoError(t, config.Validate())
+
+	assert.True(t, config.AddFloatingTags)
+	assert.Equal(t, []string{"origin", "mirror"}, config.Remotes)
+	assert.Equal(t, "none", config.Types["docs"])
+	assert.Equal(t, "minor", config.Types["feat"])
+	assert.Equal(t, []core.FileConfig{{Path: "VERSION"}}, config.Files)
+	assert.Equal(t, []core.ChangelogSectionConfig{{Type: "feat", Title: "New Features"}}, config.Changelog.Sections)
+
+	rules, err := config.ParsedBranchRules()
+	require.NoError(t, err)
+	assert.Equal(t, []core.BranchRule{
+		{Pattern: "develop", Prerelease: "beta"},
+		{Pattern: "release/*", MaxBump: core.MINOR, DisablePush: true},
+	}, rules)
+
+	stream, err := config.ReleaseStream()
+	require.NoError(t, err)
+	assert.Equal(t, "release-1.2.3", stream.TagName("1.2.3"))
+}
+
+func TestLoadConfigFile_ShouldRejectInvalidToml(t *testing.T) {
+	t.Parallel()
+
+	for _, content := range []string{"release-notes = true\n", "remotes = 3\n", "push = \n"} {
+		file := filepath.Join(t.TempDir(), ".semver.toml")
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+		config := core.DefaultConfig()
+
+		assert.ErrorIs(t, core.LoadConfigFile(file, &config), core.ErrInvalidConfig, content)
+	}
+}
+
+func TestLoadConfigFile_ShouldRejectUnknownKeys(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), ".semver.yaml")
+	require.NoError(t, os.WriteFile(file, []byte("release-notes: true\n"), 0o600))
+
+	config := core.DefaultConfig()
+
+	assert.ErrorIs(t, core.LoadConfigFile(file, &config), core.ErrInvalidConfig)
+}
+
+func TestConfig_ShouldApplyEnvironmentOverFile(t *testing.T) {
+	t.Parallel()
+
+	config := core.DefaultConfig()
+	config.Push = true
+	config.Line = "1.x"
+
+	environment := map[string]string{
+		"SEMVER_PUSH":         "false",
+		"SEMVER_REMOTES":      "origin, mirror",
+		"SEMVER_BRANCH_RULES": "develop:prerelease=beta; feature/*:push=false",
+		"SEMVER_TYPES":        "docs=minor",
+		"SEMVER_TAG_TEMPLATE": "release-{version}",
+	}
+
+	err := config.ApplyEnvironment(func(name string) string { return environment[name] })
+
+	require.NoError(t, err)
+	assert.False(t, config.Push)
+	assert.Equal(t, "1.x", config.Line)
+	assert.Equal(t, []string{"origin", "mirror"}, config.Remotes)
+	assert.Len(t, config.BranchRules, 2)
+	assert.Equal(t, "minor", config.Types["docs"])
+	assert.Equal(t, "patch", config.Types["fix"])
+	assert.Equal(t, "release-{version}", config.TagTemplate)
+}
+
+func TestConfig_ShouldRejectInvalidSettings(t *testing.T) {
+	t.Parallel()
+
+	config := core.DefaultConfig()
+	config.Types["docs"] = "huge"
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.BaseTag = "latest"
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.TagPatterns = []string{`^release_.+$`}
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.Streams = map[string]core.StreamConfig{"helm": {Tag: "helm"}}
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.TagTemplate = "release"
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.Files = []core.FileConfig{{Path: "../VERSION"}}
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	config.Changelog = &core.ChangelogConfig{Sections: []core.ChangelogSectionConfig{{Type: "feat"}}}
+
+	assert.ErrorIs(t, config.Validate(), core.ErrInvalidConfig)
+
+	config = core.DefaultConfig()
+	err := config.ApplyEnvironment(func(name string) string {
+		if name == "SEMVER_FETCH" {
+			return "maybe"
+		}
+
+		return ""
+	})
+
+	assert.ErrorIs(t, err, core.ErrInvalidConfig)
+}
+
+func TestConfig_ShouldApplySettingsToBuilder(t *testing.T) {
+	t.Parallel()
+
+	config := core.DefaultConfig()
+	config.Push = true
+	config.Line = "2.x"
+	config.BaseTag = "nearest"
+	config.TolerantTags = true
+	config.TagPatterns = []string{`^release_(.+)$`}
+	config.TagExclude = []string{"nightly-*"}
+	config.Stream = "helm"
+	config.Streams = map[string]core.StreamConfig{"helm": {Tag: "chart-{version}"}}
+	config.Types = map[string]string{"feat": "major"}
+
+	builder := core.NewCalculateCommandBuilder()
+
+	require.NoError(t, config.Apply(builder))
+
+	assert.True(t, builder.Push)
+	assert.Equal(t, core.VersionLine("2.x"), builder.Line)
+	assert.Equal(t, core.BaseTagNearest, builder.BaseTag)
+	assert.True(t, builder.TagParser.Tolerant)
+	assert.Len(t, builder.TagParser.Patterns, 1)
+	assert.False(t, builder.TagFilter.Matches("nightly-1.0.0"))
+	assert.Equal(t, core.Stream{Name: "helm", Template: "chart-{version}"}, builder.Stream)
+	assert.Equal(t, core.CommitTypes{"feat": core.MAJOR}, builder.CommitTypes)
+}
@@ -1,7 +1,10 @@
 package core
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -9,47 +12,119 @@ const (
 	MAJOR SemanticVersionComponent = iota
 	MINOR
 	PATCH
+	NONE // No release is required.
 )
 
 const (
-	commitTypeGroup                 = 2
-	breakingChangeGroup             = 3
-	lenghtOfMatch                   = 2
-	lenghtOfMatchWithBreakingChange = 3
+	commitTypeGroup                 = 1
+	breakingChangeGroup             = 2
+	lenghtOfMatch                   = 1
+	lenghtOfMatchWithBreakingChange = 2
 )
 
 type SemanticVersionComponent int
 
-// GetVersionUpdate determines the version update type (MAJOR, MINOR, PATCH) based on the conventional commit message.
+// ErrInvalidVersionComponent is returned when a semantic version component name cannot be parsed.
+var ErrInvalidVersionComponent = errors.New("invalid version component")
+
+// String returns the lower case name of the semantic version component.
+func (c SemanticVersionComponent) String() string {
+	switch c {
+	case MAJOR:
+		return "major"
+	case MINOR:
+		return "minor"
+	case PATCH:
+		return "patch"
+	case NONE:
+		return "none"
+	default:
+		return fmt.Sprintf("SemanticVersionComponent(%d)", int(c))
+	}
+}
+
+// ParseSemanticVersionComponent parses major, minor, patch or none, ignoring case.
+func ParseSemanticVersionComponent(name string) (SemanticVersionComponent, error) {
+	for _, component := range []SemanticVersionComponent{MAJOR, MINOR, PATCH, NONE} {
+		if strings.EqualFold(component.String(), name) {
+			return component, nil
+		}
+	}
+
+	return MAJOR, fmt.Errorf("%w: %q", ErrInvalidVersionComponent, name)
+}
+
+// breakingChangeType is the commit type of a message starting with BREAKING CHANGE.
+const breakingChangeType = "BREAKING CHANGE"
+
+// conventionalCommitPattern matches the type, the optional ! and the optional scope of a conventional commit.
+// Merge messages are adapted by the MergeMessageAdapters before they are matched.
+var conventionalCommitPattern = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE)(!?)(\(.*\))?: .*`)
+
+// revertType is the commit type of a revert, also used for messages in the format of git revert.
+const revertType = "revert"
+
+// gitRevertPrefix starts the subject of the message written by git revert, for example Revert "feat: add X".
+const gitRevertPrefix = `Revert "`
+
+// revertedCommitsPattern matches the hashes of the reverted commits in the This reverts commit line written
+// by git revert, or in the Refs footer recommended by the conventional commits specification.
+var revertedCommitsPattern = regexp.MustCompile(`(?m)^(?:This reverts commit|Refs:) ([0-9a-f]{7,40}(?:[, ]+[0-9a-f]{7,40})*)`)
+
+// CommitTypes maps conventional commit types to the version component they increment.
+type CommitTypes map[string]SemanticVersionComponent
+
+// DefaultCommitTypes returns the commit types recognized when no mapping is configured.
+// The chore, docs and test types do not change the released code, so they do not require a release.
+func DefaultCommitTypes() CommitTypes {
+	return CommitTypes{
+		"feat":     MINOR,
+		"fix":      PATCH,
+		"chore":    NONE,
+		"docs":     NONE,
+		"style":    PATCH,
+		"refactor": PATCH,
+		"perf":     PATCH,
+		"test":     NONE,
+	}
+}
+
+// GetVersionUpdate determines the version update type (MAJOR, MINOR, PATCH, NONE) based on the conventional commit message.
+// Merge messages are adapted by the DefaultMergeMessageAdapters first.
 func GetVersionUpdate(commitMessage string) SemanticVersionComponent {
-	// Regular expression to match commit types, including optional ! before :
-	re := regexp.MustCompile(`^(Merged PR \d+: )?(feat|fix|chore|docs|style|refactor|perf|test|BREAKING CHANGE)(!?)(\(.*\))?: .*`)
+	message, _ := DefaultMergeMessageAdapters().Adapt(commitMessage)
+
+	return DefaultCommitTypes().VersionUpdate(message)
+}
+
+// VersionUpdate determines the version update type of the conventional commit message from the commit types.
+// A breaking change of a known type is a MAJOR update. Messages of unknown types default to PATCH.
+func (t CommitTypes) VersionUpdate(commitMessage string) SemanticVersionComponent {
+	if strings.HasPrefix(commitMessage, gitRevertPrefix) {
+		if version, known := t[revertType]; known {
+			return version
+		}
+
+		return PATCH
+	}
 
 	// Extract the commit type from the commit message
-	match := re.FindStringSubmatch(commitMessage)
+	match := conventionalCommitPattern.FindStringSubmatch(commitMessage)
 	if len(match) > lenghtOfMatch {
 		commitType := match[commitTypeGroup]
 
+		version, known := t[commitType]
+		if !known && commitType != breakingChangeType {
+			return PATCH
+		}
+
 		// Check if the commit message contains "BREAKING CHANGE:" or has ! in the type
-		if strings.Contains(commitMessage, "BREAKING CHANGE") ||
+		if strings.Contains(commitMessage, breakingChangeType) ||
 			(len(match) > lenghtOfMatchWithBreakingChange && match[breakingChangeGroup] == "!") {
 			return MAJOR
 		}
 
-		// Map commit types to version updates
-		versionMap := map[string]SemanticVersionComponent{
-			"feat":     MINOR,
-			"fix":      PATCH,
-			"chore":    PATCH,
-			"docs":     PATCH,
-			"style":    PATCH,
-			"refactor": PATCH,
-			"perf":     PATCH,
-			"test":     PATCH,
-		}
-
-		// Return the corresponding version update
-		if version, ok := versionMap[commitType]; ok {
+		if known {
 			return version
 		}
 	}
@@ -57,3 +132,92 @@ func GetVersionUpdate(commitMessage string) SemanticVersionComponent {
 	// Default to PATCH if no match is found
 	return PATCH
 }
+
+// Bump returns the greatest version update of the commits, or NONE when none of them requires a release.
+// Commits reverted within the commits do not contribute, and neither do their reverts.
+func (t CommitTypes) Bump(commitLogs []*CommitLog) SemanticVersionComponent {
+	bump := NONE
+
+	for _, commit := range WithoutReverts(commitLogs) {
+		update := t.VersionUpdate(commit.Message)
+		if update < bump {
+			bump = update
+		}
+	}
+
+	return bump
+}
+
+// WithoutReverts returns the commits, newest first, without the commits reverted by a newer commit in the list
+// and without the reverts of those commits. A revert is paired with the reverted commit by the hash in its
+// message, so a revert of a commit outside the list is kept. A revert of a revert cancels the first revert
+// and keeps the original commit.
+func WithoutReverts(commitLogs []*CommitLog) []*CommitLog {
+	canceled := map[*CommitLog]bool{}
+
+	for i, revert := range commitLogs {
+		if canceled[revert] {
+			continue
+		}
+
+		hashes := revertedCommits(revert.Message)
+		paired := 0
+
+		for _, hash := range hashes {
+			reverted := findCommit(commitLogs[i+1:], hash, canceled)
+			if reverted != nil {
+				canceled[reverted] = true
+				paired++
+			}
+		}
+
+		if len(hashes) > 0 && paired == len(hashes) {
+			canceled[revert] = true
+		}
+	}
+
+	kept := make([]*CommitLog, 0, len(commitLogs))
+
+	for _, commit := range commitLogs {
+		if !canceled[commit] {
+			kept = append(kept, commit)
+		}
+	}
+
+	return kept
+}
+
+// revertedCommits returns the hashes of the commits reverted by the commit message,
+// which are only read from messages of the revert type or in the format of git revert.
+func revertedCommits(commitMessage string) []string {
+	match := conventionalCommitPattern.FindStringSubmatch(commitMessage)
+	isRevert := strings.HasPrefix(commitMessage, gitRevertPrefix) ||
+		(len(match) > lenghtOfMatch && match[commitTypeGroup] == revertType)
+
+	if !isRevert {
+		return nil
+	}
+
+	hashes := []string{}
+
+	for _, refs := range revertedCommitsPattern.FindAllStringSubmatch(commitMessage, -1) {
+		for _, hash := range strings.FieldsFunc(refs[1], func(r rune) bool { return r == ',' || r == ' ' }) {
+			if !slices.Contains(hashes, hash) {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+
+	return hashes
+}
+
+// findCommit returns the first commit that is not canceled and whose hash starts with the abbreviated hash.
+func findCommit(commitLogs []*CommitLog, hash string, canceled map[*CommitLog]bool) *CommitLog {
+	for _, commit := range commitLogs {
+		if !canceled[commit] && strings.HasPrefix(commit.Hash, hash) {
+			return commit
+		}
+	}
+
+	return nil
+}
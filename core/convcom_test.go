@@ -5,6 +5,7 @@ import (
 
 	"github.com/martoc/semver/core"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_GetVersionUpdate_FeatureUpdate(t *testing.T) {
@@ -136,3 +137,71 @@ func Test_GetVersionUpdate_AzureDevOpsPrefixBreakingChangePrefixShouldChangeMayo
 	assert.Equal(t, expectedUpdate, result,
 		"Unexpected version update for refactor update.")
 }
+
+func Test_CommitTypes_VersionUpdate(t *testing.T) {
+	t.Parallel()
+
+	commitTypes := core.CommitTypes{"feat": core.MINOR, "build": core.MINOR, "fix": core.PATCH}
+
+	assert.Equal(t, core.MINOR, commitTypes.VersionUpdate("build(deps): bump go-git"))
+	assert.Equal(t, core.MAJOR, commitTypes.VersionUpdate("build!: drop go 1.22"))
+	assert.Equal(t, core.PATCH, commitTypes.VersionUpdate("docs!: rewrite the guide"))
+	assert.Equal(t, core.MAJOR, commitTypes.VersionUpdate("BREAKING CHANGE: new api"))
+	assert.Equal(t, core.PATCH, commitTypes.VersionUpdate("update readme"))
+}
+
+func Test_CommitTypes_Bump(t *testing.T) {
+	t.Parallel()
+
+	commitTypes := core.CommitTypes{"feat": core.MINOR, "fix": core.PATCH, "docs": core.NONE, "test": core.NONE}
+
+	assert.Equal(t, core.NONE, commitTypes.Bump([]*core.CommitLog{{Message: "docs: guide"}, {Message: "test: cases"}}))
+	assert.Equal(t, core.MINOR, commitTypes.Bump([]*core.CommitLog{{Message: "docs: guide"}, {Message: "feat: api"}}))
+	assert.Equal(t, core.MAJOR, commitTypes.Bump([]*core.CommitLog{{Message: "docs!: drop the v1 guide"}}))
+	assert.Equal(t, core.NONE, commitTypes.Bump(nil))
+}
+
+func Test_ParseSemanticVersionComponent_ShouldParseNone(t *testing.T) {
+	t.Parallel()
+
+	component, err := core.ParseSemanticVersionComponent("None")
+
+	require.NoError(t, err)
+	assert.Equal(t, core.NONE, component)
+	assert.Equal(t, "none", component.String())
+}
+
+func Test_WithoutReverts_ShouldCancelRevertedCommits(t *testing.T) {
+	t.Parallel()
+
+	feature := &core.CommitLog{Hash: "1111111aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", Message: "feat(api): add X"}
+	fix := &core.CommitLog{Hash: "2222222bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", Message: "fix: a fix"}
+	gitRevert := &core.CommitLog{
+		Hash:    "3333333ccccccccccccccccccccccccccccccccc",
+		Message: "Revert \"feat(api): add X\"\n\nThis reverts commit 1111111aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa.\n",
+	}
+	released := &core.CommitLog{Hash: "4444444ddddddddddddddddddddddddddddddddd", Message: "revert: fix: old fix\n\nRefs: 9999999"}
+
+	assert.Equal(t, []*core.CommitLog{released, fix}, core.WithoutReverts([]*core.CommitLog{released, gitRevert, fix, feature}))
+
+	reapply := &core.CommitLog{
+		Hash:    "5555555eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee",
+		Message: "revert: Revert \"feat(api): add X\"\n\nRefs: 3333333",
+	}
+
+	assert.Equal(t, []*core.CommitLog{feature}, core.WithoutReverts([]*core.CommitLog{reapply, gitRevert, feature}))
+}
+
+func Test_CommitTypes_BumpShouldIgnoreRevertedCommits(t *testing.T) {
+	t.Parallel()
+
+	commitLogs := []*core.CommitLog{
+		{Hash: "b2", Message: "revert: feat(api): add X\n\nRefs: a1b2c3d"},
+		{Hash: "b1", Message: "fix: a fix"},
+		{Hash: "a1b2c3d4", Message: "feat(api): add X"},
+	}
+
+	assert.Equal(t, core.PATCH, core.DefaultCommitTypes().Bump(commitLogs))
+	assert.Equal(t, core.NONE, core.DefaultCommitTypes().Bump(append(commitLogs[:1:1], commitLogs[2])))
+	assert.Equal(t, core.NONE, core.CommitTypes{"revert": core.NONE}.VersionUpdate("Revert \"fix: a fix\""))
+}
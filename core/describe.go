@@ -0,0 +1,284 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	shortHashLength = 7
+	devPrerelease   = "dev"
+	hashPrefix      = "g"
+	// DefaultDirtyMark is appended to the development version when the working tree has uncommitted changes.
+	DefaultDirtyMark = "dirty"
+)
+
+var (
+	// ErrInvalidDescribeFormat is returned when a describe format name cannot be parsed.
+	ErrInvalidDescribeFormat = errors.New("invalid describe format")
+	// ErrInvalidDirtyMark is returned when the dirty mark is not a semantic version build identifier.
+	ErrInvalidDirtyMark = errors.New("invalid dirty mark")
+)
+
+// DescribeFormat selects the format of the development version of a build that is not released.
+type DescribeFormat int
+
+const (
+	// DescribeDev is a prerelease of the next version with the distance and the commit as build metadata,
+	// such as 1.4.0-dev.7+g1a2b3c4.
+	DescribeDev DescribeFormat = iota
+	// DescribeGit is the previous version followed by the distance and the commit like git describe,
+	// such as 1.3.2-7-g1a2b3c4.
+	DescribeGit
+)
+
+// describeFormats lists the describe formats in the order of their values.
+var describeFormats = []DescribeFormat{DescribeDev, DescribeGit}
+
+// String returns the name of the describe format.
+func (f DescribeFormat) String() string {
+	switch f {
+	case DescribeDev:
+		return "dev"
+	case DescribeGit:
+		return "git"
+	default:
+		return fmt.Sprintf("DescribeFormat(%d)", int(f))
+	}
+}
+
+// ParseDescribeFormat parses dev or git. An empty name is dev.
+func ParseDescribeFormat(name string) (DescribeFormat, error) {
+	if name == "" {
+		return DescribeDev, nil
+	}
+
+	for _, format := range describeFormats {
+		if format.String() == name {
+			return format, nil
+		}
+	}
+
+	return DescribeDev, fmt.Errorf("%w: %q, expected dev or git", ErrInvalidDescribeFormat, name)
+}
+
+// DescribeOutput represents the output of the describe command.
+type DescribeOutput struct {
+	APIVersion      string `json:"apiVersion"`
+	Version         string `json:"version"`                    // The development version, or the release at HEAD.
+	NextVersion     string `json:"next_version"`               // The version the development version is a prerelease of.
+	PreviousVersion string `json:"previous_version,omitempty"` // The version of the base tag.
+	Stream          string `json:"stream,omitempty"`
+	Distance        int    `json:"distance"` // The number of commits reachable from HEAD but not from the base tag.
+	Hash            string `json:"hash"`     // The hash of the HEAD commit.
+	Dirty           bool   `json:"dirty"`    // Indicates if the working tree has uncommitted changes, when checked.
+}
+
+// DescribeCommandBuilder is a builder for creating DescribeCommand instances.
+type DescribeCommandBuilder struct {
+	Calculate *CalculateCommandBuilder
+	Format    DescribeFormat
+	DirtyMark string
+}
+
+// NewDescribeCommandBuilder creates a new DescribeCommandBuilder instance calculating the next version
+// with the settings of the calculate builder, which never tags.
+func NewDescribeCommandBuilder(calculate *CalculateCommandBuilder) *DescribeCommandBuilder {
+	return &DescribeCommandBuilder{Calculate: calculate}
+}
+
+// SetFormat sets the format of the development version, dev by default.
+// It returns a pointer to the DescribeCommandBuilder for method chaining.
+func (b *DescribeCommandBuilder) SetFormat(format DescribeFormat) *DescribeCommandBuilder {
+	b.Format = format
+
+	return b
+}
+
+// SetDirtyMark sets the mark appended to the version when the working tree has uncommitted changes.
+// The working tree is not checked when the mark is empty.
+// It returns a pointer to the DescribeCommandBuilder for method chaining.
+func (b *DescribeCommandBuilder) SetDirtyMark(mark string) *DescribeCommandBuilder {
+	b.DirtyMark = mark
+
+	return b
+}
+
+// Build returns a Command built from the DescribeCommandBuilder.
+func (b *DescribeCommandBuilder) Build() Command {
+	return b.BuildDescribeCommand()
+}
+
+// BuildDescribeCommand returns the DescribeCommandImpl built from the DescribeCommandBuilder,
+// giving access to its typed Describe method.
+func (b *DescribeCommandBuilder) BuildDescribeCommand() *DescribeCommandImpl {
+	calculate := b.Calculate
+	if calculate == nil {
+		calculate = NewCalculateCommandBuilder()
+	}
+
+	return &DescribeCommandImpl{
+		Calculate: calculate.BuildCalculateCommand(),
+		Format:    b.Format,
+		DirtyMark: b.DirtyMark,
+	}
+}
+
+// DescribeCommandImpl describes the HEAD commit with a development version, without creating any tag.
+type DescribeCommandImpl struct {
+	Command
+	Calculate *CalculateCommandImpl // Calculates the next version, its tagging and pushing settings are not used.
+	Format    DescribeFormat        // Format of the development version.
+	DirtyMark string                // Appended when the working tree has uncommitted changes, not checked when empty.
+}
+
+// Execute executes the DescribeCommandImpl command and returns the DescribeOutput and any error encountered.
+func (d *DescribeCommandImpl) Execute(ctx context.Context) (interface{}, error) {
+	output, err := d.Describe(ctx)
+	if output == nil {
+		return "", err
+	}
+
+	return *output, err
+}
+
+// Describe calculates the next version and returns the development version of HEAD. The distance is
+// the number of commits reachable from HEAD but not from the base tag, zero when HEAD has the base tag.
+// When no commit requires a release, the next version is the next patch version, which the development
+// version is a prerelease of. A released HEAD is described by its version.
+// The tags of the remote are fetched first when fetching is enabled, and no tag is ever created.
+func (d *DescribeCommandImpl) Describe(ctx context.Context) (*DescribeOutput, error) {
+	if d.DirtyMark != "" {
+		if _, err := semver.NewBuildVersion(d.DirtyMark); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidDirtyMark, err)
+		}
+	}
+
+	c := d.Calculate
+
+	if c.Fetch || c.Prune {
+		_, err := c.Scm.FetchTags(ctx, c.Prune)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	commitLogs, err := c.Scm.GetCommitLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(commitLogs) == 0 {
+		return nil, ErrNoCommits
+	}
+
+	policy, err := c.releasePolicy(c.resolveBranch(commitLogs))
+	if err != nil {
+		return nil, err
+	}
+
+	calculation := &CalculateOutput{}
+
+	nextTag, err := c.calculateTag(calculation, commitLogs, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if !calculation.Released && len(calculation.Commits) > 0 {
+		nextTag.IncrementPatch() //nolint: errcheck // A development version sorts after the previous version
+	}
+
+	output := &DescribeOutput{
+		APIVersion:      APIVersion,
+		NextVersion:     nextTag.String(),
+		PreviousVersion: calculation.PreviousVersion,
+		Stream:          c.Stream.Name,
+		Distance:        len(calculation.Commits),
+		Hash:            commitLogs[0].Hash,
+	}
+
+	if d.DirtyMark != "" {
+		clean, errClean := c.Scm.IsClean(ctx)
+		if errClean != nil {
+			return nil, errClean
+		}
+
+		output.Dirty = !clean
+	}
+
+	output.Version = d.version(output, *nextTag)
+
+	return output, nil
+}
+
+// version returns the development version in the format of the command.
+func (d *DescribeCommandImpl) version(output *DescribeOutput, nextTag semver.Version) string {
+	mark := ""
+	if output.Dirty {
+		mark = d.DirtyMark
+	}
+
+	switch d.Format {
+	case DescribeGit:
+		return gitDescribe(output, nextTag, mark)
+	case DescribeDev:
+		return devVersion(output, nextTag, mark)
+	default:
+		return devVersion(output, nextTag, mark)
+	}
+}
+
+// devVersion returns the next version with a dev prerelease and the commit as build metadata,
+// such as 1.4.0-dev.7+g1a2b3c4.dirty, or the released version with the dirty mark.
+func devVersion(output *DescribeOutput, nextTag semver.Version, mark string) string {
+	version := nextTag
+	version.Build = nil
+
+	if output.Distance > 0 {
+		version.Pre = append(append([]semver.PRVersion{}, version.Pre...),
+			semver.PRVersion{VersionStr: devPrerelease},
+			semver.PRVersion{VersionNum: uint64(output.Distance), IsNum: true},
+		)
+		version.Build = []string{hashPrefix + shortHash(output.Hash)}
+	}
+
+	if mark != "" {
+		version.Build = append(version.Build, mark)
+	}
+
+	return version.String()
+}
+
+// gitDescribe returns the previous version followed by the distance and the commit like git describe,
+// such as 1.3.2-7-g1a2b3c4-dirty, or the released version with the dirty mark.
+func gitDescribe(output *DescribeOutput, nextTag semver.Version, mark string) string {
+	description := nextTag.String()
+
+	if output.Distance > 0 {
+		description = output.PreviousVersion
+		if description == "" {
+			description = "0.0.0"
+		}
+
+		description += "-" + strconv.Itoa(output.Distance) + "-" + hashPrefix + shortHash(output.Hash)
+	}
+
+	if mark != "" {
+		description += "-" + mark
+	}
+
+	return description
+}
+
+// shortHash returns the abbreviated commit hash.
+func shortHash(hash string) string {
+	if len(hash) > shortHashLength {
+		return hash[:shortHashLength]
+	}
+
+	return hash
+}
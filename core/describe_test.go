@@ -0,0 +1,231 @@
+package core_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDescribeFormat(t *testing.T) {
+	t.Parallel()
+
+	for name, expected := range map[string]core.DescribeFormat{"": core.DescribeDev, "dev": core.DescribeDev, "git": core.DescribeGit} {
+		format, err := core.ParseDescribeFormat(name)
+
+		require.NoError(t, err)
+		assert.Equal(t, expected, format)
+	}
+
+	_, err := core.ParseDescribeFormat("semver")
+
+	assert.ErrorIs(t, err, core.ErrInvalidDescribeFormat)
+}
+
+// initUnreleasedCommits creates a repository tagged v1.3.2 with a feature and a fix since.
+func initUnreleasedCommits(t *testing.T) (string, *git.Repository) {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.3.2", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "feat: add X")
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	return path, repo
+}
+
+func TestDescribeCommandImpl_ShouldDescribeUnreleasedCommits(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format  core.DescribeFormat
+		version string
+	}{
+		{core.DescribeDev, "1.4.0-dev.2+g"},
+		{core.DescribeGit, "1.3.2-2-g"},
+	}
+
+	for _, test := range tests {
+		path, repo := initUnreleasedCommits(t)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+
+		output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path).SetPush(true)).
+			SetFormat(test.format).
+			BuildDescribeCommand().
+			Describe(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, test.version+head.Hash().String()[:7], output.Version, test.format)
+		assert.Equal(t, "1.4.0", output.NextVersion)
+		assert.Equal(t, "1.3.2", output.PreviousVersion)
+		assert.Equal(t, 2, output.Distance)
+		assert.False(t, output.Dirty)
+
+		tags, err := repo.Tags()
+		require.NoError(t, err)
+
+		count := 0
+		require.NoError(t, tags.ForEach(func(*plumbing.Reference) error {
+			count++
+
+			return nil
+		}))
+		assert.Equal(t, 1, count, "describe must not tag")
+	}
+}
+
+func TestDescribeCommandImpl_ShouldDescribeReleasedHead(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	head := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.3.2", head, nil)
+	require.NoError(t, err)
+
+	for _, format := range []core.DescribeFormat{core.DescribeDev, core.DescribeGit} {
+		output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path)).
+			SetFormat(format).
+			BuildDescribeCommand().
+			Describe(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.3.2", output.Version, format)
+		assert.Equal(t, 0, output.Distance)
+		assert.Equal(t, head.String(), output.Hash)
+	}
+}
+
+func TestDescribeCommandImpl_ShouldMarkDirtyWorkingTree(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format core.DescribeFormat
+		suffix string
+	}{
+		{core.DescribeDev, ".dirty"},
+		{core.DescribeGit, "-dirty"},
+	}
+
+	for _, test := range tests {
+		path, repo := initUnreleasedCommits(t)
+		require.NoError(t, os.WriteFile(filepath.Join(path, "file.txt"), []byte("changed"), 0o600))
+
+		output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path)).
+			SetFormat(test.format).
+			SetDirtyMark(core.DefaultDirtyMark).
+			BuildDescribeCommand().
+			Describe(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, output.Dirty)
+		assert.Equal(t, test.suffix, output.Version[len(output.Version)-len(test.suffix):], test.format)
+
+		worktree, err := repo.Worktree()
+		require.NoError(t, err)
+		require.NoError(t, worktree.Reset(&git.ResetOptions{Mode: git.HardReset}))
+
+		output, err = core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path)).
+			SetFormat(test.format).
+			SetDirtyMark(core.DefaultDirtyMark).
+			BuildDescribeCommand().
+			Describe(context.Background())
+
+		require.NoError(t, err)
+		assert.False(t, output.Dirty)
+		assert.NotContains(t, output.Version, "dirty")
+	}
+}
+
+func TestDescribeCommandImpl_ShouldDescribeNextPatchWhenNoReleaseIsRequired(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.3.2", base, nil)
+	require.NoError(t, err)
+
+	head := gittest.CommitFile(t, repo, "docs: a change")
+
+	output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetCommitTypes(core.CommitTypes{"docs": core.NONE})).
+		BuildDescribeCommand().
+		Describe(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.2", output.PreviousVersion)
+	assert.Equal(t, "1.3.3", output.NextVersion, "the next version must agree with the development version")
+	assert.Equal(t, "1.3.3-dev.1+g"+head.String()[:7], output.Version)
+}
+
+func TestDescribeCommandImpl_ShouldCountCommitsOfNoFastForwardMerge(t *testing.T) {
+	t.Parallel()
+
+	path, merge := initNoFastForwardMerge(t, "Merge branch 'feature'")
+
+	for _, test := range []struct {
+		format  core.DescribeFormat
+		version string
+	}{
+		{core.DescribeDev, "1.1.0-dev.2+g"},
+		{core.DescribeGit, "1.0.0-2-g"},
+	} {
+		output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path)).
+			SetFormat(test.format).
+			BuildDescribeCommand().
+			Describe(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, output.Distance, "like git describe, the merge and the merged commit")
+		assert.Equal(t, test.version+merge.String()[:7], output.Version, test.format)
+	}
+}
+
+func TestDescribeCommandImpl_ShouldCountNoCommitWhenHeadHasTheBaseTag(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	head := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.3.2", head, nil)
+	require.NoError(t, err)
+
+	output, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetBranch("develop").
+		SetBranchRules([]core.BranchRule{{Pattern: "develop", Prerelease: "beta"}})).
+		BuildDescribeCommand().
+		Describe(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, output.Distance)
+	assert.Equal(t, output.NextVersion, output.Version)
+}
+
+func TestDescribeCommandImpl_ShouldRejectInvalidDirtyMark(t *testing.T) {
+	t.Parallel()
+
+	path, _ := initUnreleasedCommits(t)
+
+	_, err := core.NewDescribeCommandBuilder(core.NewCalculateCommandBuilder().SetPath(path)).
+		SetDirtyMark("not clean").
+		BuildDescribeCommand().
+		Describe(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrInvalidDirtyMark)
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	gitDirName = ".git"
+)
+
+// ErrRepositoryNotFound is returned when no git repository can be found at or above a path.
+var ErrRepositoryNotFound = errors.New("repository not found")
+
+// FindRepositoryRoot returns the root of the git repository containing path.
+// It walks up the parent directories until it finds a directory with a .git entry,
+// either a directory or a file pointing at a gitdir as used by linked worktrees,
+// or a directory laid out as a bare repository.
+func FindRepositoryRoot(path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if info, errStat := os.Stat(dir); errStat == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	for {
+		if _, errStat := os.Stat(filepath.Join(dir, gitDirName)); errStat == nil {
+			return dir, nil
+		}
+
+		if isBareRepository(dir) {
+			return dir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("%w: %s", ErrRepositoryNotFound, path)
+		}
+
+		dir = parent
+	}
+}
+
+// isBareRepository reports whether dir has the layout of a bare git repository.
+func isBareRepository(dir string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
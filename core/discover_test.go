@@ -0,0 +1,116 @@
+package core_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRepositoryRoot_ShouldReturnWorkingTreeRoot(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	gittest.CommitFile(t, repo, "feat: initial commit")
+
+	root, err := core.FindRepositoryRoot(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, path, root)
+}
+
+func TestFindRepositoryRoot_ShouldWalkUpFromSubdirectory(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	gittest.CommitFile(t, repo, "feat: initial commit")
+
+	subdirectory := filepath.Join(path, "modules", "api")
+	require.NoError(t, os.MkdirAll(subdirectory, 0o755))
+
+	root, err := core.FindRepositoryRoot(subdirectory)
+
+	assert.NoError(t, err)
+	assert.Equal(t, path, root)
+}
+
+func TestFindRepositoryRoot_ShouldFailOutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	root, err := core.FindRepositoryRoot(t.TempDir())
+
+	assert.Empty(t, root)
+	assert.ErrorIs(t, err, core.ErrRepositoryNotFound)
+}
+
+func TestGitRepoImpl_PlainOpenShouldOpenSubdirectory(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	hash := gittest.CommitFile(t, repo, "feat: initial commit")
+
+	subdirectory := filepath.Join(path, "modules")
+	require.NoError(t, os.MkdirAll(subdirectory, 0o755))
+
+	gitRepo := &core.GitRepoImpl{}
+	require.NoError(t, gitRepo.PlainOpen(context.Background(), subdirectory))
+
+	head, err := gitRepo.Head()
+
+	assert.NoError(t, err)
+	assert.Equal(t, hash, head.Hash())
+}
+
+func TestGitRepoImpl_PlainOpenShouldOpenBareRepository(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	hash := gittest.CommitFile(t, repo, "feat: initial commit")
+
+	barePath := filepath.Join(t.TempDir(), "mirror.git")
+	_, err := git.PlainClone(barePath, true, &git.CloneOptions{URL: path})
+	require.NoError(t, err)
+
+	gitRepo := &core.GitRepoImpl{}
+	require.NoError(t, gitRepo.PlainOpen(context.Background(), barePath))
+
+	head, err := gitRepo.Head()
+
+	assert.NoError(t, err)
+	assert.Equal(t, hash, head.Hash())
+}
+
+func TestGitRepoImpl_PlainOpenShouldOpenLinkedWorktree(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	hash := gittest.CommitFile(t, repo, "feat: initial commit")
+
+	// Lay out a linked worktree the way `git worktree add` does.
+	adminDir := filepath.Join(path, ".git", "worktrees", "linked")
+	worktreePath := filepath.Join(t.TempDir(), "linked")
+
+	require.NoError(t, os.MkdirAll(adminDir, 0o755))
+	require.NoError(t, os.MkdirAll(worktreePath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(adminDir, "HEAD"), []byte(hash.String()+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(adminDir, "commondir"), []byte("../..\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(adminDir, "gitdir"), []byte(filepath.Join(worktreePath, ".git")+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(worktreePath, ".git"), []byte("gitdir: "+adminDir+"\n"), 0o600))
+
+	gitRepo := &core.GitRepoImpl{}
+	require.NoError(t, gitRepo.PlainOpen(context.Background(), worktreePath))
+
+	head, err := gitRepo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, head.Hash())
+
+	commit, err := gitRepo.CommitObject(hash)
+	assert.NoError(t, err)
+	assert.Equal(t, "feat: initial commit", commit.Message)
+}
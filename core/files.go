@@ -0,0 +1,96 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	versionFilePermissions = 0o644
+	versionGroup           = 1
+	submatchIndexes        = 2 // Start and end offsets of each group in a submatch index.
+)
+
+var (
+	// ErrInvalidFileUpdater is returned when the path or the pattern of a file updater cannot be used.
+	ErrInvalidFileUpdater = errors.New("invalid file updater")
+	// ErrVersionNotFound is returned when the pattern of a file updater does not match the file.
+	ErrVersionNotFound = errors.New("version not found")
+)
+
+// FileUpdater writes the released version into a file of the repository, such as VERSION or package.json.
+type FileUpdater struct {
+	Path    string         // Path of the file relative to the repository root.
+	Pattern *regexp.Regexp // Pattern whose first group is replaced by the version, the whole file when nil.
+}
+
+// NewFileUpdater returns the updater of the file at the path relative to the repository root. The first group
+// of every match of the pattern is replaced by the version, and the whole file is the version when the pattern
+// is empty. It returns ErrInvalidFileUpdater when the path leaves the repository or the pattern has no group.
+func NewFileUpdater(path, pattern string) (FileUpdater, error) {
+	if !filepath.IsLocal(path) {
+		return FileUpdater{}, fmt.Errorf("%w: %q is not a path inside the repository", ErrInvalidFileUpdater, path)
+	}
+
+	updater := FileUpdater{Path: filepath.Clean(path)}
+	if pattern == "" {
+		return updater, nil
+	}
+
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return FileUpdater{}, fmt.Errorf("%w: %s: %w", ErrInvalidFileUpdater, path, err)
+	}
+
+	if expression.NumSubexp() < versionGroup {
+		return FileUpdater{}, fmt.Errorf("%w: %s: pattern %q has no group capturing the version",
+			ErrInvalidFileUpdater, path, pattern)
+	}
+
+	updater.Pattern = expression
+
+	return updater, nil
+}
+
+// Update writes the version into the file in the repository at root. A file without a pattern is created
+// when it does not exist. It returns ErrVersionNotFound when the pattern does not match the file.
+func (u FileUpdater) Update(root, version string) error {
+	file := filepath.Join(root, u.Path)
+
+	if u.Pattern == nil {
+		return os.WriteFile(file, []byte(version+"\n"), versionFilePermissions)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	matches := u.Pattern.FindAllSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return fmt.Errorf("%w: %s does not match %q", ErrVersionNotFound, u.Path, u.Pattern)
+	}
+
+	var updated strings.Builder
+
+	last := 0
+
+	for _, match := range matches {
+		start, end := match[submatchIndexes*versionGroup], match[submatchIndexes*versionGroup+1]
+		if start < 0 {
+			continue // The group did not take part in the match
+		}
+
+		updated.Write(content[last:start])
+		updated.WriteString(version)
+		last = end
+	}
+
+	updated.Write(content[last:])
+
+	return os.WriteFile(file, []byte(updated.String()), versionFilePermissions)
+}
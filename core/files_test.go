@@ -0,0 +1,60 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileUpdater_ShouldWriteVersionFile(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	updater, err := core.NewFileUpdater("VERSION", "")
+	require.NoError(t, err)
+	require.NoError(t, updater.Update(root, "1.2.3"))
+
+	content, err := os.ReadFile(filepath.Join(root, "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3\n", string(content))
+}
+
+func TestFileUpdater_ShouldReplaceVersionMatchedByPattern(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	file := filepath.Join(root, "package.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"name": "app", "version": "0.1.0", "private": true}`), 0o600))
+
+	updater, err := core.NewFileUpdater("package.json", `"version": "([^"]+)"`)
+	require.NoError(t, err)
+	require.NoError(t, updater.Update(root, "1.2.3"))
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "app", "version": "1.2.3", "private": true}`, string(content))
+
+	updater, err = core.NewFileUpdater("package.json", `"revision": "([^"]+)"`)
+	require.NoError(t, err)
+	assert.ErrorIs(t, updater.Update(root, "1.2.4"), core.ErrVersionNotFound)
+}
+
+func TestNewFileUpdater_ShouldRejectInvalidUpdaters(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct{ path, pattern string }{
+		{"../VERSION", ""},
+		{"/etc/VERSION", ""},
+		{"VERSION", "version: .+"},
+		{"VERSION", "version: (.+"},
+	} {
+		_, err := core.NewFileUpdater(test.path, test.pattern)
+
+		assert.ErrorIs(t, err, core.ErrInvalidFileUpdater, test)
+	}
+}
@@ -0,0 +1,78 @@
+package core
+
+import (
+	"strconv"
+
+	"github.com/blang/semver/v4"
+)
+
+const (
+	latestTagName = "latest"
+	stableTagName = "stable"
+)
+
+// floatingTag is a tag that follows the greatest release of a set of versions.
+type floatingTag struct {
+	name    string
+	inScope func(tag semver.Version) bool // Reports whether a tag competes for the floating tag.
+}
+
+// floatingTags returns the floating tags of the stream requested for version: vX and vX.Y when addFloatingTags
+// is set, and the latest and stable tags when addLatestTag and addStableTag are set.
+func floatingTags(version semver.Version, stream Stream, addFloatingTags, addLatestTag, addStableTag bool) []floatingTag {
+	tags := []floatingTag{}
+
+	if addFloatingTags {
+		major := strconv.FormatUint(version.Major, 10)
+		minor := major + "." + strconv.FormatUint(version.Minor, 10)
+
+		tags = append(tags,
+			floatingTag{name: stream.TagName(major), inScope: func(tag semver.Version) bool {
+				return tag.Major == version.Major
+			}},
+			floatingTag{name: stream.TagName(minor), inScope: func(tag semver.Version) bool {
+				return tag.Major == version.Major && tag.Minor == version.Minor
+			}},
+		)
+	}
+
+	everyVersion := func(semver.Version) bool { return true }
+
+	if addLatestTag {
+		tags = append(tags, floatingTag{name: stream.FloatingTagName(latestTagName), inScope: everyVersion})
+	}
+
+	if addStableTag {
+		tags = append(tags, floatingTag{name: stream.FloatingTagName(stableTagName), inScope: everyVersion})
+	}
+
+	return tags
+}
+
+// planFloatingTags splits the floating tags into the ones that move to version and the ones skipped
+// because a greater stable release exists in their scope, such as a 1.4.7 backport released after 1.5.0
+// which must not take v1. Prerelease versions never move floating tags.
+func planFloatingTags(version semver.Version, candidates []floatingTag, existing []*semver.Version) ([]string, []string) {
+	var moved, skipped []string
+
+	for _, candidate := range candidates {
+		if len(version.Pre) == 0 && isGreatestInScope(version, candidate, existing) {
+			moved = append(moved, candidate.name)
+		} else {
+			skipped = append(skipped, candidate.name)
+		}
+	}
+
+	return moved, skipped
+}
+
+// isGreatestInScope reports whether no stable tag in the scope of the floating tag is greater than version.
+func isGreatestInScope(version semver.Version, candidate floatingTag, existing []*semver.Version) bool {
+	for _, tag := range existing {
+		if len(tag.Pre) == 0 && candidate.inScope(*tag) && tag.GT(version) {
+			return false
+		}
+	}
+
+	return true
+}
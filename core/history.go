@@ -0,0 +1,179 @@
+package core
+
+import (
+	"errors"
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// HistoryMode selects the commits of the history that are analyzed.
+type HistoryMode int
+
+const (
+	// HistoryAll walks every ancestor of HEAD in the default order of git log.
+	HistoryAll HistoryMode = iota
+	// HistoryFirstParent only walks the first parent of each commit, so that the commits and tags of merged
+	// branches are ignored and each merge counts as a single commit.
+	HistoryFirstParent
+	// HistoryMergeAware walks the first parent of each commit and follows each merge commit with the commits
+	// it merged since the merge base, ignoring their tags.
+	HistoryMergeAware
+)
+
+// firstParentIter is an object.CommitIter over the first parents of a commit. In merge aware mode each
+// merge commit is followed by the commits it merged.
+type firstParentIter struct {
+	repo       GitRepo
+	next       plumbing.Hash
+	mergeAware bool
+	pending    []*object.Commit       // Merged commits returned before the next first parent.
+	merged     map[plumbing.Hash]bool // Commits returned because a merge commit merged them.
+}
+
+// newFirstParentIter returns an iterator over the first parents of the commit.
+func newFirstParentIter(repo GitRepo, from plumbing.Hash, mergeAware bool) *firstParentIter {
+	return &firstParentIter{repo: repo, next: from, mergeAware: mergeAware, merged: map[plumbing.Hash]bool{}}
+}
+
+// Next returns the next commit, or io.EOF after the root commit. A missing parent, at the boundary of
+// a shallow clone, returns plumbing.ErrObjectNotFound.
+func (i *firstParentIter) Next() (*object.Commit, error) {
+	if len(i.pending) > 0 {
+		commit := i.pending[0]
+		i.pending = i.pending[1:]
+
+		return commit, nil
+	}
+
+	if i.next.IsZero() {
+		return nil, io.EOF
+	}
+
+	commit, err := i.repo.CommitObject(i.next)
+	if err != nil {
+		return nil, err
+	}
+
+	i.next = plumbing.ZeroHash
+	if commit.NumParents() > 0 {
+		i.next = commit.ParentHashes[0]
+	}
+
+	if i.mergeAware && commit.NumParents() > 1 {
+		i.pending, err = mergedCommits(commit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, merged := range i.pending {
+			i.merged[merged.Hash] = true
+		}
+	}
+
+	return commit, nil
+}
+
+// ForEach calls the callback for each remaining commit until it returns an error.
+// storer.ErrStop stops the iteration without an error.
+func (i *firstParentIter) ForEach(callback func(*object.Commit) error) error {
+	for {
+		commit, err := i.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = callback(commit)
+		if errors.Is(err, storer.ErrStop) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Close releases the iterator.
+func (i *firstParentIter) Close() {
+	i.pending = nil
+}
+
+// isMerged reports whether the commit was returned because a merge commit merged it.
+func (i *firstParentIter) isMerged(hash plumbing.Hash) bool {
+	return i.merged[hash]
+}
+
+// mergedCommits returns the commits reachable from the other parents of the merge commit and not from its
+// first parent, in pre-order. Commits missing from a shallow clone end the walk.
+func mergedCommits(merge *object.Commit) ([]*object.Commit, error) {
+	first, err := merge.Parent(0)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[plumbing.Hash]bool{}
+	commits := []*object.Commit{}
+
+	for index := 1; index < merge.NumParents(); index++ {
+		parent, errParent := merge.Parent(index)
+		if errors.Is(errParent, plumbing.ErrObjectNotFound) {
+			continue
+		}
+
+		if errParent != nil {
+			return nil, errParent
+		}
+
+		bases, errBase := parent.MergeBase(first)
+		if errBase != nil && !errors.Is(errBase, plumbing.ErrObjectNotFound) {
+			return nil, errBase
+		}
+
+		// Every ancestor of a merge base is reachable from the first parent, including the ones
+		// a branch reaches through merges of the first parent history into the branch.
+		for _, base := range bases {
+			err = walkCommits(base, excluded, func(commit *object.Commit) {
+				excluded[commit.Hash] = true
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = walkCommits(parent, excluded, func(commit *object.Commit) {
+			excluded[commit.Hash] = true
+			commits = append(commits, commit)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return commits, nil
+}
+
+// walkCommits calls the callback for the commit and its ancestors in pre-order, skipping the excluded
+// commits and their ancestors. The walk ends without an error at commits missing from a shallow clone.
+func walkCommits(from *object.Commit, excluded map[plumbing.Hash]bool, callback func(*object.Commit)) error {
+	err := object.NewCommitPreorderIter(from, excluded, nil).ForEach(func(commit *object.Commit) error {
+		callback(commit)
+
+		return nil
+	})
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil
+	}
+
+	return err
+}
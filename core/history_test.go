@@ -0,0 +1,151 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mergedBranch holds the commits of a repository where a feature branch with an old tag was merged into master.
+type mergedBranch struct {
+	path    string
+	base    plumbing.Hash // Tagged v1.0.0 on master.
+	feature plumbing.Hash // Tagged v9.0.0 on the feature branch.
+	fix     plumbing.Hash // Committed on master after the branch was created.
+	merge   plumbing.Hash // Merges the feature branch into master.
+}
+
+// initMergedBranch creates a repository with a feature branch merged into master with a GitHub merge message.
+func initMergedBranch(t *testing.T) mergedBranch {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+	branch := mergedBranch{path: path}
+
+	branch.base = gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", branch.base, nil)
+	require.NoError(t, err)
+
+	branch.feature = gittest.CommitFile(t, repo, "feat(api): add X")
+	_, err = repo.CreateTag("v9.0.0", branch.feature, nil)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: branch.base, Mode: git.HardReset}))
+
+	branch.fix = gittest.CommitFile(t, repo, "fix: a fix")
+
+	branch.merge, err = worktree.Commit("Merge pull request #1 from org/feature\n\nAdd X", &git.CommitOptions{
+		Author:            &object.Signature{Name: "Sarah Connor", Email: "sarah@example.com", When: time.Now()},
+		Parents:           []plumbing.Hash{branch.fix, branch.feature},
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+
+	return branch
+}
+
+func TestScmGit_GetCommitLogShouldFollowMergesInMergeAwareMode(t *testing.T) {
+	t.Parallel()
+
+	branch := initMergedBranch(t)
+
+	commitLogs, err := core.NewScmGitBuilder().
+		SetPath(branch.path).
+		SetHistory(core.HistoryMergeAware).
+		Build().
+		GetCommitLog(context.Background())
+
+	require.NoError(t, err)
+
+	hashes := []string{}
+	for _, commit := range commitLogs {
+		hashes = append(hashes, commit.Hash)
+	}
+
+	assert.Equal(t, []string{branch.merge.String(), branch.feature.String(), branch.fix.String(), branch.base.String()}, hashes)
+	assert.Empty(t, commitLogs[1].Tags)
+	assert.Len(t, commitLogs[3].Tags, 1)
+}
+
+func TestCalculateCommandImpl_ShouldCalculateFromHistoryMode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		history     core.HistoryMode
+		nextVersion string
+	}{
+		{core.HistoryAll, "9.0.1"}, // The tag of the branch leaks in, only the fix and the merge are since v9.0.0
+		{core.HistoryFirstParent, "1.0.1"},
+		{core.HistoryMergeAware, "1.1.0"},
+	}
+
+	for _, test := range tests {
+		branch := initMergedBranch(t)
+
+		result, err := core.NewCalculateCommandBuilder().
+			SetPath(branch.path).
+			SetHistory(test.history).
+			SetDisableTagging(true).
+			BuildCalculateCommand().
+			Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, test.nextVersion, result.NextVersion, test.history)
+	}
+}
+
+// initNoFastForwardMerge creates a repository tagged v1.0.0 where a feature branch created from the tagged
+// commit was merged with --no-ff and the message, so that the first parent of the merge is the tagged commit.
+func initNoFastForwardMerge(t *testing.T, message string) (string, plumbing.Hash) {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	feature := gittest.CommitFile(t, repo, "feat: new api")
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}))
+
+	merge, err := worktree.Commit(message, &git.CommitOptions{
+		Author:            &object.Signature{Name: "Sarah Connor", Email: "sarah@example.com", When: time.Now()},
+		Parents:           []plumbing.Hash{base, feature},
+		AllowEmptyCommits: true,
+	})
+	require.NoError(t, err)
+
+	return path, merge
+}
+
+func TestCalculateCommandImpl_ShouldBumpByCommitsOfNoFastForwardMerge(t *testing.T) {
+	t.Parallel()
+
+	for _, message := range []string{"Merge pull request #1 from org/feature", "Merge branch 'feature'"} {
+		path, _ := initNoFastForwardMerge(t, message)
+
+		result, err := core.NewCalculateCommandBuilder().
+			SetPath(path).
+			SetDisableTagging(true).
+			BuildCalculateCommand().
+			Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.True(t, result.Released, message)
+		assert.Equal(t, "1.1.0", result.NextVersion, message)
+		assert.Equal(t, "minor", result.Bump, message)
+	}
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+var (
+	// ErrInvalidVersionLine is returned when a version line constraint cannot be parsed.
+	ErrInvalidVersionLine = errors.New("invalid version line")
+	// ErrVersionOutsideLine is returned when the calculated version would leave the version line.
+	ErrVersionOutsideLine = errors.New("version outside of version line")
+)
+
+// VersionLine is a constraint on the versions a maintenance line may release, such as 1.x,
+// 1.4.x or >=1.0.0 <2.0.0. Only tags inside the line are used as the base version.
+// The zero value does not restrict versions.
+type VersionLine string
+
+// ParseVersionLine validates a version line constraint.
+func ParseVersionLine(constraint string) (VersionLine, error) {
+	constraint = strings.TrimSpace(constraint)
+	if constraint == "" {
+		return "", nil
+	}
+
+	if _, err := semver.ParseRange(constraint); err != nil {
+		return "", fmt.Errorf("%w: %q: %w", ErrInvalidVersionLine, constraint, err)
+	}
+
+	return VersionLine(constraint), nil
+}
+
+// Contains reports whether the version belongs to the line.
+// Prerelease identifiers are ignored, so 2.0.0-beta.1 is not part of the 1.x line.
+func (l VersionLine) Contains(version semver.Version) bool {
+	if l == "" {
+		return true
+	}
+
+	contains, err := semver.ParseRange(string(l))
+	if err != nil {
+		return false
+	}
+
+	return contains(semver.Version{Major: version.Major, Minor: version.Minor, Patch: version.Patch})
+}
+
+// Filter returns the tags that belong to the line.
+func (l VersionLine) Filter(tags []*semver.Version) []*semver.Version {
+	if l == "" {
+		return tags
+	}
+
+	selected := []*semver.Version{}
+
+	for _, tag := range tags {
+		if l.Contains(*tag) {
+			selected = append(selected, tag)
+		}
+	}
+
+	return selected
+}
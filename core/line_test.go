@@ -0,0 +1,55 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersionLine_ShouldAcceptWildcardsAndRanges(t *testing.T) {
+	t.Parallel()
+
+	for _, constraint := range []string{"1.x", "1.4.x", ">=1.0.0 <2.0.0", ""} {
+		line, err := core.ParseVersionLine(constraint)
+
+		assert.NoError(t, err, constraint)
+		assert.Equal(t, core.VersionLine(constraint), line)
+	}
+}
+
+func TestParseVersionLine_ShouldRejectInvalidConstraints(t *testing.T) {
+	t.Parallel()
+
+	_, err := core.ParseVersionLine("one.x")
+
+	assert.ErrorIs(t, err, core.ErrInvalidVersionLine)
+}
+
+func TestVersionLine_Contains(t *testing.T) {
+	t.Parallel()
+
+	line := core.VersionLine("1.x")
+
+	assert.True(t, line.Contains(semver.MustParse("1.0.0")))
+	assert.True(t, line.Contains(semver.MustParse("1.9.3")))
+	assert.True(t, line.Contains(semver.MustParse("1.9.3-beta.1")))
+	assert.False(t, line.Contains(semver.MustParse("2.0.0")))
+	assert.False(t, line.Contains(semver.MustParse("2.0.0-beta.1")))
+	assert.False(t, line.Contains(semver.MustParse("0.9.0")))
+	assert.True(t, core.VersionLine("").Contains(semver.MustParse("3.0.0")))
+}
+
+func TestVersionLine_Filter(t *testing.T) {
+	t.Parallel()
+
+	v1 := semver.MustParse("1.4.7")
+	v2 := semver.MustParse("2.0.0")
+	v3 := semver.MustParse("3.1.0")
+	tags := []*semver.Version{&v1, &v2, &v3}
+
+	assert.Equal(t, []*semver.Version{&v1}, core.VersionLine("1.x").Filter(tags))
+	assert.Equal(t, []*semver.Version{&v2, &v3}, core.VersionLine(">=2.0.0").Filter(tags))
+	assert.Equal(t, tags, core.VersionLine("").Filter(tags))
+}
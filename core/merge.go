@@ -0,0 +1,166 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	azureDevOpsMergePattern = regexp.MustCompile(`^Merged PR \d+: `)
+	gitHubMergePattern      = regexp.MustCompile(`^Merge pull request #\d+ from \S+$`)
+	gitHubSquashPattern     = regexp.MustCompile(`^(.+) \(#\d+\)$`)
+	gitLabMergePattern      = regexp.MustCompile(`^Merge branch '.+' into '.+'$`)
+	gitLabMergeFooter       = regexp.MustCompile(`(?m)^See merge request \S+!\d+$`)
+	bitbucketMergePattern   = regexp.MustCompile(`^Merged in \S+ \(pull request #\d+\)$`)
+)
+
+// MergeMessageAdapter extracts the conventional commit message from the message of a merge commit
+// or of a squash merge created by a forge.
+type MergeMessageAdapter interface {
+	// Adapt returns the conventional commit message and true when the message is in the format of the adapter,
+	// or false when it is not. The conventional commit message is empty when the merge message does not
+	// have one, so that only the merged commits are analyzed.
+	Adapt(commitMessage string) (string, bool)
+}
+
+// MergeMessageAdapters is a list of adapters, the first one recognizing a message adapts it.
+type MergeMessageAdapters []MergeMessageAdapter
+
+// DefaultMergeMessageAdapters returns the adapters of the merge messages of Azure DevOps, GitHub, GitLab and Bitbucket.
+func DefaultMergeMessageAdapters() MergeMessageAdapters {
+	return MergeMessageAdapters{
+		AzureDevOpsMergeAdapter{},
+		GitHubMergeAdapter{},
+		GitHubSquashAdapter{},
+		GitLabMergeAdapter{},
+		BitbucketMergeAdapter{},
+	}
+}
+
+// Adapt returns the conventional commit message extracted by the first adapter recognizing the message.
+func (a MergeMessageAdapters) Adapt(commitMessage string) (string, bool) {
+	for _, adapter := range a {
+		message, adapted := adapter.Adapt(commitMessage)
+		if adapted {
+			return message, true
+		}
+	}
+
+	return commitMessage, false
+}
+
+// AdaptCommits returns the commits with their conventional commit messages, leaving out the merge commits
+// without one. The commits are copied when their message is adapted.
+func (a MergeMessageAdapters) AdaptCommits(commitLogs []*CommitLog) []*CommitLog {
+	adapted := make([]*CommitLog, 0, len(commitLogs))
+
+	for _, commit := range commitLogs {
+		message, ok := a.Adapt(commit.Message)
+		if !ok {
+			adapted = append(adapted, commit)
+
+			continue
+		}
+
+		if message == "" {
+			continue
+		}
+
+		adaptedCommit := *commit
+		adaptedCommit.Message = message
+		adapted = append(adapted, &adaptedCommit)
+	}
+
+	return adapted
+}
+
+// AzureDevOpsMergeAdapter adapts Azure DevOps merge messages such as "Merged PR 12: feat: add X".
+type AzureDevOpsMergeAdapter struct{}
+
+// Adapt removes the Merged PR prefix from the message.
+func (AzureDevOpsMergeAdapter) Adapt(commitMessage string) (string, bool) {
+	prefix := azureDevOpsMergePattern.FindString(commitMessage)
+	if prefix == "" {
+		return commitMessage, false
+	}
+
+	return strings.TrimPrefix(commitMessage, prefix), true
+}
+
+// GitHubMergeAdapter adapts GitHub merge messages such as "Merge pull request #12 from org/branch",
+// whose body is the title of the pull request.
+type GitHubMergeAdapter struct{}
+
+// Adapt returns the body of the message when it is a conventional commit message.
+func (GitHubMergeAdapter) Adapt(commitMessage string) (string, bool) {
+	subject, body := splitCommitMessage(commitMessage)
+	if !gitHubMergePattern.MatchString(subject) {
+		return commitMessage, false
+	}
+
+	return conventionalOrEmpty(body), true
+}
+
+// GitHubSquashAdapter adapts GitHub squash merge messages, whose subject ends with the pull request
+// number such as "feat: add X (#12)".
+type GitHubSquashAdapter struct{}
+
+// Adapt removes the pull request number from the subject of the message.
+func (GitHubSquashAdapter) Adapt(commitMessage string) (string, bool) {
+	subject, rest, _ := strings.Cut(commitMessage, "\n")
+
+	match := gitHubSquashPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return commitMessage, false
+	}
+
+	if rest == "" {
+		return match[1], true
+	}
+
+	return match[1] + "\n" + rest, true
+}
+
+// GitLabMergeAdapter adapts GitLab merge messages such as "Merge branch 'x' into 'main'" with
+// a "See merge request group/project!12" footer, whose body starts with the title of the merge request.
+type GitLabMergeAdapter struct{}
+
+// Adapt returns the body of the message when it is a conventional commit message.
+func (GitLabMergeAdapter) Adapt(commitMessage string) (string, bool) {
+	subject, body := splitCommitMessage(commitMessage)
+	if !gitLabMergePattern.MatchString(subject) || !gitLabMergeFooter.MatchString(body) {
+		return commitMessage, false
+	}
+
+	return conventionalOrEmpty(body), true
+}
+
+// BitbucketMergeAdapter adapts Bitbucket merge messages such as "Merged in feature/x (pull request #7)",
+// whose body starts with the title of the pull request.
+type BitbucketMergeAdapter struct{}
+
+// Adapt returns the body of the message when it is a conventional commit message.
+func (BitbucketMergeAdapter) Adapt(commitMessage string) (string, bool) {
+	subject, body := splitCommitMessage(commitMessage)
+	if !bitbucketMergePattern.MatchString(subject) {
+		return commitMessage, false
+	}
+
+	return conventionalOrEmpty(body), true
+}
+
+// splitCommitMessage returns the subject and the body of the commit message.
+func splitCommitMessage(commitMessage string) (string, string) {
+	subject, body, _ := strings.Cut(strings.TrimSpace(commitMessage), "\n")
+
+	return strings.TrimSpace(subject), strings.TrimSpace(body)
+}
+
+// conventionalOrEmpty returns the message when it is a conventional commit message, or an empty string.
+func conventionalOrEmpty(commitMessage string) string {
+	if !conventionalCommitPattern.MatchString(commitMessage) {
+		return ""
+	}
+
+	return commitMessage
+}
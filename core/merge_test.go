@@ -0,0 +1,58 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/martoc/semver/core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultMergeMessageAdapters_ShouldAdaptForgeMessages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		message  string
+		expected string
+		adapted  bool
+	}{
+		{"Merged PR 12345: feat: improve code", "feat: improve code", true},
+		{"Merge pull request #12 from org/branch\n\nfeat(api): add X", "feat(api): add X", true},
+		{"Merge pull request #12 from org/branch\n\nAdd X", "", true},
+		{"Merge pull request #12 from org/branch", "", true},
+		{"fix: handle empty input (#12)\n\n* fix: handle empty input", "fix: handle empty input\n\n* fix: handle empty input", true},
+		{
+			"Merge branch 'feature/x' into 'main'\n\nfeat: add X\n\nSee merge request group/project!34",
+			"feat: add X\n\nSee merge request group/project!34", true,
+		},
+		{"Merge branch 'feature/x' into 'main'", "Merge branch 'feature/x' into 'main'", false},
+		{"Merged in feature/x (pull request #7)\n\nfix!: drop Y\n\n* fix!: drop Y", "fix!: drop Y\n\n* fix!: drop Y", true},
+		{"feat: add X", "feat: add X", false},
+	}
+
+	for _, test := range tests {
+		message, adapted := core.DefaultMergeMessageAdapters().Adapt(test.message)
+
+		assert.Equal(t, test.expected, message, test.message)
+		assert.Equal(t, test.adapted, adapted, test.message)
+	}
+}
+
+func TestMergeMessageAdapters_ShouldLeaveOutMergeCommitsWithoutConventionalMessage(t *testing.T) {
+	t.Parallel()
+
+	merge := &core.CommitLog{Hash: "c2", Message: "Merge pull request #12 from org/branch\n\nAdd X"}
+	squash := &core.CommitLog{Hash: "c1", Message: "feat: add Y (#11)"}
+	feature := &core.CommitLog{Hash: "c0", Message: "feat: add X"}
+
+	commitLogs := core.DefaultMergeMessageAdapters().AdaptCommits([]*core.CommitLog{merge, squash, feature})
+
+	assert.Equal(t, []*core.CommitLog{{Hash: "c1", Message: "feat: add Y"}, feature}, commitLogs)
+	assert.Equal(t, "feat: add Y (#11)", squash.Message)
+}
+
+func TestGetVersionUpdate_ShouldUseMergedPullRequestTitle(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, core.MAJOR, core.GetVersionUpdate("Merged in feature/x (pull request #7)\n\nfix!: drop Y"))
+	assert.Equal(t, core.MINOR, core.GetVersionUpdate("Merge pull request #12 from org/branch\n\nfeat: add X"))
+}
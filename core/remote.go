@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/sirupsen/logrus"
+)
+
+// GitRepoMemory is an implementation of the GitRepo interface that clones a remote repository
+// into memory, so that versions can be calculated without a working copy.
+type GitRepoMemory struct {
+	GitRepoImpl
+	Credentials Credentials        // Credentials used to authenticate against the remote.
+	Depth       int                // Number of commits to clone, zero clones the complete history.
+	Logger      logrus.FieldLogger // Logger of ignored errors, the shared logger when nil.
+}
+
+// PlainOpen clones the repository at the URL into memory storage without a worktree.
+// Only the branch at the remote HEAD and the tags are cloned, and the clone is reused
+// by later calls. Returns an error if the repository cannot be cloned, or the context error when the
+// context is canceled during the clone.
+func (g *GitRepoMemory) PlainOpen(ctx context.Context, url string) error {
+	if g.repo != nil {
+		return nil
+	}
+
+	auth, err := g.Credentials.AuthMethod(g.Logger, "", url)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{
+		URL:          url,
+		Auth:         auth,
+		SingleBranch: true,
+		Depth:        g.Depth,
+		Tags:         git.AllTags,
+	})
+	if err != nil {
+		return err
+	}
+
+	g.repo = repo
+
+	return nil
+}
+
+// RemoteScmGitBuilder is a builder for creating ScmGit instances backed by an in-memory clone of a remote repository.
+type RemoteScmGitBuilder struct {
+	URL         string
+	Deepen      bool
+	History     HistoryMode
+	TagParser   TagParser
+	Credentials Credentials
+	Logger      logrus.FieldLogger
+}
+
+// NewRemoteScmGitBuilder creates a new RemoteScmGitBuilder instance.
+func NewRemoteScmGitBuilder() *RemoteScmGitBuilder {
+	return &RemoteScmGitBuilder{}
+}
+
+// SetURL sets the URL of the remote repository.
+func (b *RemoteScmGitBuilder) SetURL(url string) *RemoteScmGitBuilder {
+	b.URL = url
+
+	return b
+}
+
+// SetDeepen sets whether the clone starts shallow and is deepened until a release tag is reachable,
+// instead of cloning the complete history.
+func (b *RemoteScmGitBuilder) SetDeepen(deepen bool) *RemoteScmGitBuilder {
+	b.Deepen = deepen
+
+	return b
+}
+
+// SetHistory sets the commits of the history walked by GetCommitLog, every ancestor of HEAD by default.
+func (b *RemoteScmGitBuilder) SetHistory(history HistoryMode) *RemoteScmGitBuilder {
+	b.History = history
+
+	return b
+}
+
+// SetTagParser sets the parser of the tag names, which only accepts semantic versions by default.
+func (b *RemoteScmGitBuilder) SetTagParser(parser TagParser) *RemoteScmGitBuilder {
+	b.TagParser = parser
+
+	return b
+}
+
+// SetCredentials sets the credentials used to clone from and push to the remote repository.
+func (b *RemoteScmGitBuilder) SetCredentials(credentials Credentials) *RemoteScmGitBuilder {
+	b.Credentials = credentials
+
+	return b
+}
+
+// SetLogger sets the logger of the progress and ignored errors.
+func (b *RemoteScmGitBuilder) SetLogger(log logrus.FieldLogger) *RemoteScmGitBuilder {
+	b.Logger = log
+
+	return b
+}
+
+// Build creates a new ScmGit instance that clones the remote repository on first use.
+// Tags created by the ScmGit only exist in memory until they are pushed back to the remote.
+func (b *RemoteScmGitBuilder) Build() Scm {
+	repo := &GitRepoMemory{Credentials: b.Credentials, Logger: b.Logger}
+	if b.Deepen {
+		repo.Depth = minimumDeepenDepth
+	}
+
+	return &ScmGit{
+		Path:        b.URL,
+		Repo:        repo,
+		Deepen:      b.Deepen,
+		History:     b.History,
+		TagParser:   b.TagParser,
+		Credentials: b.Credentials,
+		Logger:      b.Logger,
+	}
+}
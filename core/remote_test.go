@@ -0,0 +1,96 @@
+package core_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteScmGit_ShouldCalculateVersionFromURL(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	tagged := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", tagged, nil)
+	require.NoError(t, err)
+	gittest.CommitFile(t, source, "feat: new feature")
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetRepositoryURL("file://" + sourcePath).
+		Build().
+		Execute(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", result.(core.CalculateOutput).NextVersion)
+	assert.Equal(t, "master", result.(core.CalculateOutput).Branch)
+
+	// The tag is only created in memory.
+	_, err = source.Tag("v1.1.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestRemoteScmGit_ShouldPushTagsBackToURL(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	gittest.CommitFile(t, source, "feat: initial commit")
+	head := gittest.CommitFile(t, source, "fix: first fix")
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetRepositoryURL("file://" + remotePath).
+		SetAddFloatingTags(true).
+		SetPush(true).
+		Build().
+		Execute(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.0", result.(core.CalculateOutput).NextVersion)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	for _, name := range []string{"v0.1.0", "v0", "v0.1"} {
+		ref, errTag := remote.Tag(name)
+		require.NoError(t, errTag, name)
+		assert.Equal(t, head, ref.Hash(), name)
+	}
+}
+
+func TestRemoteScmGit_ShouldDeepenShallowCloneUntilTagIsReached(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	tagged := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", tagged, nil)
+	require.NoError(t, err)
+
+	for range 60 {
+		gittest.CommitFile(t, source, "fix: another fix")
+	}
+
+	scm := core.NewRemoteScmGitBuilder().SetURL("file://" + sourcePath).SetDeepen(true).Build()
+
+	commitLogs, err := scm.GetCommitLog(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, commitLogs, 61)
+	assert.Equal(t, "1.0.0", commitLogs[60].Tags[0].String())
+}
+
+func TestRemoteScmGit_ShouldFailIfRepositoryCannotBeCloned(t *testing.T) {
+	t.Parallel()
+
+	scm := core.NewRemoteScmGitBuilder().SetURL("file://" + filepath.Join(t.TempDir(), "missing.git")).Build()
+
+	_, err := scm.GetCommitLog(context.Background())
+
+	assert.Error(t, err)
+}
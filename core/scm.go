@@ -1,6 +1,11 @@
 package core
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/blang/semver/v4"
@@ -9,7 +14,52 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/storer"
-	"github.com/martoc/semver/logger"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRemoteName  = "origin"
+	minimumDeepenDepth = 50
+	shallowFileName    = "shallow" // Boundary commits of a shallow clone, in the git directory.
+	deepenFactor       = 2
+	tagsRefSpec        = "+refs/tags/*:refs/tags/*"
+)
+
+const (
+	PushStatusPushed   = "pushed"
+	PushStatusUpToDate = "up-to-date"
+	PushStatusRejected = "rejected"
+	PushStatusSkipped  = "skipped"
+)
+
+const (
+	TagChangeAdded   = "added"
+	TagChangeUpdated = "updated"
+	TagChangePruned  = "pruned"
+)
+
+const (
+	RollbackStatusRestored = "restored"
+	RollbackStatusDeleted  = "deleted"
+	RollbackStatusFailed   = "failed"
+)
+
+var (
+	// ErrNoCommits is returned when the repository has no commit to calculate a version for.
+	ErrNoCommits = errors.New("no commits")
+	// ErrDirtyTree is returned when a clean working tree is required and it has uncommitted changes.
+	ErrDirtyTree = errors.New("working tree has uncommitted changes")
+	// ErrShallowRepository is returned when the history of a shallow clone ends before any release tag.
+	ErrShallowRepository = errors.New("shallow repository")
+	// ErrPushRejected is returned when the remote rejects a pushed reference.
+	ErrPushRejected = errors.New("push rejected")
+	// ErrTagConflict is returned when a release tag already exists on a different commit, locally or on
+	// the remote, or when a floating tag on the remote moved since it was fetched.
+	ErrTagConflict = errors.New("tag conflict")
+	// ErrRollbackFailed is returned when a tag created by a failed release cannot be restored.
+	ErrRollbackFailed = errors.New("rollback failed")
 )
 
 //go:generate ${GOPATH}/bin/mockgen -destination=./commit_iter_mock.go -package=core github.com/go-git/go-git/v5/plumbing/object CommitIter
@@ -25,25 +75,63 @@ type CommitLog struct {
 	Author     string            // The author of the commit.
 	Head       bool              // Indicates if the commit is the HEAD commit.
 	BranchName string            // The name of the branch the commit belongs to.
+	Parents    []string          // The hashes of the parents of the commit.
+}
+
+// PushResult reports the outcome of pushing a single reference to a remote repository.
+type PushResult struct {
+	Remote string `json:"remote"`          // The name of the remote.
+	Ref    string `json:"ref"`             // The full reference name, for example refs/tags/v1.2.3.
+	Forced bool   `json:"forced"`          // Indicates if the reference was force pushed.
+	Status string `json:"status"`          // One of pushed, up-to-date, rejected or skipped.
+	Error  string `json:"error,omitempty"` // The reason the reference was rejected.
+}
+
+// TagChange reports a local tag changed by fetching the tags of the remote repository.
+type TagChange struct {
+	Ref      string `json:"ref"`                // The full reference name, for example refs/tags/v1.2.3.
+	Status   string `json:"status"`             // One of added, updated or pruned.
+	Hash     string `json:"hash,omitempty"`     // The hash of the tag after the fetch.
+	Previous string `json:"previous,omitempty"` // The hash of the tag before the fetch.
+}
+
+// RollbackResult reports how a reference touched by a failed release was restored.
+type RollbackResult struct {
+	Ref    string `json:"ref"`             // The full reference name, for example refs/tags/v1.2.3.
+	Status string `json:"status"`          // One of restored, deleted or failed.
+	Hash   string `json:"hash,omitempty"`  // The hash the reference was restored to.
+	Error  string `json:"error,omitempty"` // The reason the reference could not be restored.
 }
 
 // Scm is an interface that defines the methods for interacting with a source control management system.
 type Scm interface {
-	GetCommitLog() ([]*CommitLog, error) // GetCommitLog retrieves the commit history of the Git repository.
-	Tag(name, hash string, floating bool) error
-	Push() error
+	GetCommitLog(ctx context.Context) ([]*CommitLog, error)          // GetCommitLog retrieves the commit history of the Git repository.
+	GetTags() ([]*semver.Version, error)                             // GetTags retrieves every semantic version tag in the repository.
+	FetchTags(ctx context.Context, prune bool) ([]TagChange, error)  // FetchTags updates the local tags from the remote repository.
+	Tag(ctx context.Context, name, hash string, floating bool) error // Tag creates or moves a tag to the commit.
+	Push(ctx context.Context) ([]PushResult, error)                  // Push publishes the tags created by Tag.
+	Rollback() ([]RollbackResult, error)                             // Rollback restores the tags created by Tag to their previous state.
+	IsClean(ctx context.Context) (bool, error)                       // IsClean reports whether the working tree has no uncommitted changes.
 }
 
 // GitRepo is an interface that defines the methods for interacting with a Git repository.
 type GitRepo interface {
-	PlainOpen(string) error
+	PlainOpen(ctx context.Context, path string) error
 	Head() (*plumbing.Reference, error)
 	Log(*git.LogOptions) (object.CommitIter, error)
 	Tags() (storer.ReferenceIter, error)
 	CommitObject(plumbing.Hash) (*object.Commit, error)
 	CreateTag(name string, hash plumbing.Hash, opts *git.CreateTagOptions) (*plumbing.Reference, error)
 	DeleteTag(name string) error
-	Push(opts *git.PushOptions) error
+	Push(ctx context.Context, opts *git.PushOptions) error
+	Fetch(ctx context.Context, opts *git.FetchOptions) error
+	Shallow() ([]plumbing.Hash, error)
+	SetShallow(hashes []plumbing.Hash) error
+	RemoteURL(name string) (string, error)
+	Reference(name plumbing.ReferenceName) (*plumbing.Reference, error)
+	SetReference(ref *plumbing.Reference) error
+	ListRemote(ctx context.Context, name string, auth transport.AuthMethod) ([]*plumbing.Reference, error)
+	IsClean() (bool, error)
 }
 
 // GitRepoImpl is an implementation of the GitRepo interface.
@@ -51,11 +139,18 @@ type GitRepoImpl struct {
 	repo *git.Repository
 }
 
-// PlainOpen opens a Git repository at the specified path.
+// PlainOpen opens the Git repository containing the specified path.
+// The path may be a subdirectory of a working tree, a linked worktree or a bare repository.
 // It initializes the GitRepoImpl struct with the opened repository.
 // Returns an error if the repository cannot be opened.
-func (g *GitRepoImpl) PlainOpen(path string) error {
-	repo, err := git.PlainOpen(path)
+// Opening a local repository does not block, so the context is not used.
+func (g *GitRepoImpl) PlainOpen(_ context.Context, path string) error {
+	root, err := FindRepositoryRoot(path)
+	if err != nil {
+		return err
+	}
+
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
 	g.repo = repo
 
 	return err
@@ -100,20 +195,131 @@ func (g *GitRepoImpl) DeleteTag(name string) error {
 }
 
 // Push pushes the changes to the remote repository.
-func (g *GitRepoImpl) Push(opts *git.PushOptions) error {
-	return g.repo.Push(opts)
+// The push is aborted when the context is canceled.
+func (g *GitRepoImpl) Push(ctx context.Context, opts *git.PushOptions) error {
+	return g.repo.PushContext(ctx, opts)
+}
+
+// Fetch fetches references and objects from a remote repository.
+// The fetch is aborted when the context is canceled.
+func (g *GitRepoImpl) Fetch(ctx context.Context, opts *git.FetchOptions) error {
+	return g.repo.FetchContext(ctx, opts)
+}
+
+// Shallow returns the boundary commits of a shallow repository as recorded in .git/shallow.
+// It returns an empty slice when the repository has its complete history.
+func (g *GitRepoImpl) Shallow() ([]plumbing.Hash, error) {
+	return g.repo.Storer.Shallow()
+}
+
+// SetShallow replaces the boundary commits recorded in .git/shallow, deleting the file when there are none
+// so that git sees the complete history.
+func (g *GitRepoImpl) SetShallow(hashes []plumbing.Hash) error {
+	storage, isFilesystem := g.repo.Storer.(*filesystem.Storage)
+	if !isFilesystem || len(hashes) > 0 {
+		return g.repo.Storer.SetShallow(hashes)
+	}
+
+	err := storage.Filesystem().Remove(shallowFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// RemoteURL returns the first URL configured for the named remote.
+func (g *GitRepoImpl) RemoteURL(name string) (string, error) {
+	remote, err := g.repo.Remote(name)
+	if err != nil {
+		return "", err
+	}
+
+	return remote.Config().URLs[0], nil
+}
+
+// Reference returns the reference with the given name without resolving it.
+func (g *GitRepoImpl) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	return g.repo.Reference(name, false)
+}
+
+// SetReference stores the reference, replacing any existing reference with the same name.
+func (g *GitRepoImpl) SetReference(ref *plumbing.Reference) error {
+	return g.repo.Storer.SetReference(ref)
+}
+
+// ListRemote returns the references advertised by the named remote.
+func (g *GitRepoImpl) ListRemote(ctx context.Context, name string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	remote, err := g.repo.Remote(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+}
+
+// IsClean reports whether the working tree has no staged or unstaged changes to tracked files.
+// Untracked files are ignored, and repositories without a working tree are always clean.
+func (g *GitRepoImpl) IsClean() (bool, error) {
+	worktree, err := g.repo.Worktree()
+	if errors.Is(err, git.ErrIsBareRepository) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range status {
+		if file.Staging == git.Untracked && file.Worktree == git.Untracked {
+			continue
+		}
+
+		if file.Staging != git.Unmodified || file.Worktree != git.Unmodified {
+			return false, nil
+		}
+	}
+
+	return true, nil
 }
 
 // ScmGit is an implementation of the Scm interface for Git repositories.
 type ScmGit struct {
-	Path string
-	Repo GitRepo
+	Path        string
+	Repo        GitRepo
+	Deepen      bool
+	History     HistoryMode        // Commits of the history walked by GetCommitLog.
+	TagParser   TagParser          // Parses the tag names into versions.
+	Remotes     []string           // Remotes to push to, the first one is also fetched from. Defaults to origin.
+	Credentials Credentials        // Credentials used to authenticate against the remotes.
+	Logger      logrus.FieldLogger // Logger of the progress and ignored errors, the shared logger when nil.
+	createdTags []createdTag
+}
+
+// createdTag is a tag created by ScmGit.Tag and not yet pushed.
+type createdTag struct {
+	name     string
+	floating bool
+	existing bool // The release tag already pointed to the commit, so it is pushed but never rolled back.
+	hash     plumbing.Hash
+	previous *plumbing.Reference // The reference the tag replaced, nil when the tag did not exist.
 }
 
 // ScmGitBuilder is a builder for creating ScmGit instances.
 type ScmGitBuilder struct {
-	Path string
-	Repo GitRepo
+	Path        string
+	Repo        GitRepo
+	Deepen      bool
+	History     HistoryMode
+	TagParser   TagParser
+	Remotes     []string
+	Credentials Credentials
+	Logger      logrus.FieldLogger
 }
 
 // NewScmGitBuilder creates a new ScmGitBuilder instance.
@@ -135,6 +341,48 @@ func (b *ScmGitBuilder) SetRepo(repo GitRepo) *ScmGitBuilder {
 	return b
 }
 
+// SetDeepen enables fetching more history from the remote when a shallow clone has no release tag.
+func (b *ScmGitBuilder) SetDeepen(deepen bool) *ScmGitBuilder {
+	b.Deepen = deepen
+
+	return b
+}
+
+// SetHistory sets the commits of the history walked by GetCommitLog, every ancestor of HEAD by default.
+func (b *ScmGitBuilder) SetHistory(history HistoryMode) *ScmGitBuilder {
+	b.History = history
+
+	return b
+}
+
+// SetTagParser sets the parser of the tag names, which only accepts semantic versions by default.
+func (b *ScmGitBuilder) SetTagParser(parser TagParser) *ScmGitBuilder {
+	b.TagParser = parser
+
+	return b
+}
+
+// SetRemotes sets the remotes tags are pushed to. The first remote is also used for fetching.
+func (b *ScmGitBuilder) SetRemotes(remotes []string) *ScmGitBuilder {
+	b.Remotes = remotes
+
+	return b
+}
+
+// SetCredentials sets the credentials used to authenticate against the remotes.
+func (b *ScmGitBuilder) SetCredentials(credentials Credentials) *ScmGitBuilder {
+	b.Credentials = credentials
+
+	return b
+}
+
+// SetLogger sets the logger of the progress and ignored errors.
+func (b *ScmGitBuilder) SetLogger(log logrus.FieldLogger) *ScmGitBuilder {
+	b.Logger = log
+
+	return b
+}
+
 // Build creates a new Scm instance based on the builder configuration.
 func (b *ScmGitBuilder) Build() Scm {
 	if b.Repo == nil {
@@ -142,8 +390,14 @@ func (b *ScmGitBuilder) Build() Scm {
 	}
 
 	return &ScmGit{
-		Path: b.Path,
-		Repo: b.Repo,
+		Path:        b.Path,
+		Repo:        b.Repo,
+		Deepen:      b.Deepen,
+		History:     b.History,
+		TagParser:   b.TagParser,
+		Remotes:     b.Remotes,
+		Credentials: b.Credentials,
+		Logger:      b.Logger,
 	}
 }
 
@@ -152,57 +406,324 @@ func (b *ScmGitBuilder) Build() Scm {
 // along with associated information such as the commit hash, message,
 // tags, author, and date.
 // If an error occurs during the retrieval process, it is returned as the second value.
-func (s *ScmGit) GetCommitLog() ([]*CommitLog, error) {
+// The walk stops with the context error when the context is canceled.
+func (s *ScmGit) GetCommitLog(ctx context.Context) ([]*CommitLog, error) {
 	// Open the Git repository
-	err := s.Repo.PlainOpen(s.Path)
+	err := s.Repo.PlainOpen(ctx, s.Path)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get the HEAD reference
 	ref, err := s.Repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, fmt.Errorf("%w: %w", ErrNoCommits, err)
+	}
+
 	if err != nil {
 		return nil, err
 	}
 
-	// Retrieve the commit history starting from HEAD
-	commitIter, err := s.Repo.Log(&git.LogOptions{From: ref.Hash()})
+	commitLogs, truncated, err := s.readCommitLog(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
 
+	if truncated && !hasTags(commitLogs) {
+		return s.deepenCommitLog(ctx, ref, commitLogs)
+	}
+
+	return commitLogs, nil
+}
+
+// readCommitLog walks the commit history starting from the given reference.
+// It also reports whether the walk stopped at a missing parent, which happens
+// at the boundary of a shallow clone.
+func (s *ScmGit) readCommitLog(ctx context.Context, ref *plumbing.Reference) ([]*CommitLog, bool, error) {
+	// Retrieve the commit history starting from HEAD
+	commitIter, err := s.commitIter(ref.Hash())
+	if err != nil {
+		return nil, false, err
+	}
+
+	firstParents, _ := commitIter.(*firstParentIter)
+
 	commitLogs := []*CommitLog{}
+	truncated := false
 	// Iterate over commits and display commit information using for loop
 	for {
+		if err = ctx.Err(); err != nil {
+			return nil, false, err
+		}
+
 		commit, errCommitIter := commitIter.Next()
 		if commit == nil || errCommitIter != nil {
+			truncated = errors.Is(errCommitIter, plumbing.ErrObjectNotFound)
+
 			break
 		}
 
 		isHead := false
+		branchName := ""
 		// Get the tags associated with the commit
 		tags, errTags := s.Repo.Tags()
 		if errTags != nil {
-			logger.GetInstance().Error(errTags)
+			s.log().Error(errTags)
 		}
 
 		tagNames := s.getTags(commit, tags)
+		if firstParents != nil && firstParents.isMerged(commit.Hash) {
+			tagNames = nil // Tags of merged branches are not releases of the first parent history
+		}
 
 		if ref.Hash() == commit.Hash {
 			isHead = true
+
+			if ref.Name().IsBranch() {
+				branchName = ref.Name().Short()
+			}
 		}
 
 		commitLogs = append(commitLogs, &CommitLog{
-			Hash:    commit.Hash.String(),
-			Message: commit.Message,
-			Tags:    tagNames,
-			Head:    isHead,
-			Author:  commit.Author.Name,
-			Date:    commit.Author.When,
+			Hash:       commit.Hash.String(),
+			Message:    commit.Message,
+			Tags:       tagNames,
+			Head:       isHead,
+			Author:     commit.Author.Name,
+			Date:       commit.Author.When,
+			BranchName: branchName,
+			Parents:    parentHashes(commit),
 		})
 	}
 
-	return commitLogs, nil //nolint:nilerr
+	return commitLogs, truncated, nil
+}
+
+// parentHashes returns the hashes of the parents of the commit, nil for a root commit.
+func parentHashes(commit *object.Commit) []string {
+	if len(commit.ParentHashes) == 0 {
+		return nil
+	}
+
+	hashes := make([]string, 0, len(commit.ParentHashes))
+	for _, hash := range commit.ParentHashes {
+		hashes = append(hashes, hash.String())
+	}
+
+	return hashes
+}
+
+// commitIter returns an iterator over the commits of the history mode, starting from the commit.
+func (s *ScmGit) commitIter(from plumbing.Hash) (object.CommitIter, error) {
+	if s.History == HistoryFirstParent || s.History == HistoryMergeAware {
+		return newFirstParentIter(s.Repo, from, s.History == HistoryMergeAware), nil
+	}
+
+	return s.Repo.Log(&git.LogOptions{From: from})
+}
+
+// deepenCommitLog handles a commit history that ends before any release tag.
+// When the repository is a shallow clone and deepening is enabled, it fetches
+// increasingly deeper history from the remote until a tagged commit is reached
+// or the complete history is available. Otherwise it returns ErrShallowRepository.
+func (s *ScmGit) deepenCommitLog(ctx context.Context, ref *plumbing.Reference, commitLogs []*CommitLog) ([]*CommitLog, error) {
+	shallows, err := s.Repo.Shallow()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(shallows) == 0 {
+		return commitLogs, nil
+	}
+
+	if !s.Deepen {
+		return nil, fmt.Errorf("%w: no release tag found in the %d available commits, "+
+			"fetch more history or enable deepening", ErrShallowRepository, len(commitLogs))
+	}
+
+	depth := len(commitLogs)
+
+	for {
+		depth = max(depth*deepenFactor, minimumDeepenDepth)
+
+		s.log().Debugln("Deepening shallow repository to", depth, "commits")
+
+		remote := s.remotes()[0]
+
+		auth, errAuth := s.auth(remote)
+		if errAuth != nil {
+			return nil, errAuth
+		}
+
+		err = s.Repo.Fetch(ctx, &git.FetchOptions{RemoteName: remote, Depth: depth, Tags: git.AllTags, Auth: auth})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, err
+		}
+
+		err = s.pruneShallow()
+		if err != nil {
+			return nil, err
+		}
+
+		deeperLogs, truncated, errLog := s.readCommitLog(ctx, ref)
+		if errLog != nil {
+			return nil, errLog
+		}
+
+		if !truncated || hasTags(deeperLogs) {
+			return deeperLogs, nil
+		}
+
+		if len(deeperLogs) <= len(commitLogs) {
+			return nil, fmt.Errorf("%w: remote %s has no more history to fetch", ErrShallowRepository, s.remotes()[0])
+		}
+
+		commitLogs = deeperLogs
+	}
+}
+
+// pruneShallow removes from .git/shallow the boundary commits whose parents were fetched by deepening.
+// go-git only adds the new boundary commits, and git would otherwise see the previous ones as root commits.
+func (s *ScmGit) pruneShallow() error {
+	shallows, err := s.Repo.Shallow()
+	if err != nil {
+		return err
+	}
+
+	boundaries := []plumbing.Hash{}
+
+	for _, hash := range shallows {
+		if !s.hasParents(hash) {
+			boundaries = append(boundaries, hash)
+		}
+	}
+
+	if len(boundaries) == len(shallows) {
+		return nil
+	}
+
+	return s.Repo.SetShallow(boundaries)
+}
+
+// hasParents reports whether the commit and all of its parents are in the repository.
+func (s *ScmGit) hasParents(hash plumbing.Hash) bool {
+	commit, err := s.Repo.CommitObject(hash)
+	if err != nil {
+		return false
+	}
+
+	for _, parent := range commit.ParentHashes {
+		_, err = s.Repo.CommitObject(parent)
+		if err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FetchTags fetches the tags of the first remote, replacing local tags that differ from the remote,
+// so that releases are calculated from the tags other pipelines have already pushed.
+// When prune is set, local tags deleted from the remote are deleted too.
+// It returns the local tags that were added, updated or pruned.
+func (s *ScmGit) FetchTags(ctx context.Context, prune bool) ([]TagChange, error) {
+	err := s.Repo.PlainOpen(ctx, s.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := s.remotes()[0]
+
+	auth, err := s.auth(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.tagHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repo.Fetch(ctx, &git.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{tagsRefSpec},
+		Tags:       git.NoTags,
+		Prune:      prune,
+		Auth:       auth,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fetch tags from %s: %w", remote, err)
+	}
+
+	after, err := s.tagHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	return tagChanges(before, after), nil
+}
+
+// tagHashes returns the hash of every local tag by reference name.
+func (s *ScmGit) tagHashes() (map[plumbing.ReferenceName]plumbing.Hash, error) {
+	tags, err := s.Repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := map[plumbing.ReferenceName]plumbing.Hash{}
+
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		hashes[ref.Name()] = ref.Hash()
+
+		return nil
+	})
+
+	return hashes, err
+}
+
+// tagChanges compares the local tags before and after a fetch, sorted by reference name.
+func tagChanges(before, after map[plumbing.ReferenceName]plumbing.Hash) []TagChange {
+	var changes []TagChange
+
+	for name, hash := range after {
+		previous, found := before[name]
+
+		switch {
+		case !found:
+			changes = append(changes, TagChange{Ref: name.String(), Status: TagChangeAdded, Hash: hash.String()})
+		case previous != hash:
+			changes = append(changes, TagChange{
+				Ref: name.String(), Status: TagChangeUpdated, Hash: hash.String(), Previous: previous.String(),
+			})
+		}
+	}
+
+	for name, previous := range before {
+		if _, found := after[name]; !found {
+			changes = append(changes, TagChange{Ref: name.String(), Status: TagChangePruned, Previous: previous.String()})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Ref < changes[j].Ref
+	})
+
+	return changes
+}
+
+// hasTags reports whether any of the commits has a release tag.
+func hasTags(commitLogs []*CommitLog) bool {
+	for _, commitLog := range commitLogs {
+		if len(commitLog.Tags) > 0 {
+			return true
+		}
+	}
+
+	return false
 }
 
 // getTags returns a slice of semver.Version representing the tags associated with the given commit.
@@ -222,14 +743,18 @@ func (s *ScmGit) getTags(commit *object.Commit, tags storer.ReferenceIter) []*se
 
 		tagCommit, errCommit := s.Repo.CommitObject(tag.Hash())
 		if errCommit != nil {
-			logger.GetInstance().Error(tag.Name(), " - ", tag.Hash(), ": ", errCommit)
+			s.log().Debug(tag.Name(), " - ", tag.Hash(), ": ", errCommit)
 		}
 
 		if tagCommit != nil && tagCommit.Hash == commit.Hash {
-			version, errSemver := semver.Make(s.cleanVersion(tag.Name().Short()))
+			version, legacy, errSemver := s.TagParser.Parse(tag.Name().Short())
 			if errSemver != nil {
-				logger.GetInstance().Error(tag.Name().Short(), ": ", errSemver)
+				s.log().Debug(tag.Name().Short(), ": ", errSemver)
 			} else {
+				if legacy {
+					s.log().Debugln("Normalized legacy tag", tag.Name().Short(), "to", version)
+				}
+
 				tagNames = append(tagNames, &version)
 			}
 		}
@@ -238,40 +763,332 @@ func (s *ScmGit) getTags(commit *object.Commit, tags storer.ReferenceIter) []*se
 	return tagNames
 }
 
-// cleanVersion removes the leading 'v' character from the given tagName if it exists.
-// It returns the cleaned tagName.
-func (s *ScmGit) cleanVersion(tagName string) string {
-	if tagName[0] == 'v' {
-		return tagName[1:]
+// GetTags returns every tag in the repository that is a semantic version,
+// whether or not its commit is reachable from HEAD. Other tags are ignored.
+// The repository must have been opened by GetCommitLog.
+func (s *ScmGit) GetTags() ([]*semver.Version, error) {
+	tags, err := s.Repo.Tags()
+	if err != nil {
+		return nil, err
 	}
 
-	return tagName
+	versions := []*semver.Version{}
+
+	err = tags.ForEach(func(tag *plumbing.Reference) error {
+		version, _, errSemver := s.TagParser.Parse(tag.Name().Short())
+		if errSemver == nil {
+			versions = append(versions, &version)
+		}
+
+		return nil
+	})
+
+	return versions, err
 }
 
 // Tag creates a new tag with the given name and hash in the Git repository.
-// It returns an error if the tag creation fails.
-func (s *ScmGit) Tag(name, hash string, floating bool) error {
+// Floating tags are deleted first so they can move to the new commit. A release tag that already
+// exists on the commit returns git.ErrTagExists, while one on a different commit returns ErrTagConflict.
+// Created tags are remembered with the reference they replaced, so that Push only publishes
+// the tags of this run and Rollback can restore them. A release tag that already exists on the commit
+// is remembered too, so that Push publishes it to the remotes that do not have it yet, but it is never
+// rolled back.
+// It returns an error if the tag creation fails, or the context error without creating the tag
+// when the context is canceled.
+func (s *ScmGit) Tag(ctx context.Context, name, hash string, floating bool) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
 	commitHash := plumbing.NewHash(hash)
 
+	previous, err := s.Repo.Reference(plumbing.NewTagReferenceName(name))
+	if err != nil && !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+
+	if !floating && previous != nil {
+		if previous.Hash() == commitHash {
+			s.createdTags = append(s.createdTags, createdTag{name: name, existing: true, hash: commitHash, previous: previous})
+
+			return git.ErrTagExists
+		}
+
+		return fmt.Errorf("%w: %s already exists on %s", ErrTagConflict, name, previous.Hash())
+	}
+
 	if floating {
-		err := s.Repo.DeleteTag(name)
+		err = s.Repo.DeleteTag(name)
 		if err != nil {
-			logger.GetInstance().Println(err)
+			s.log().Debugln(err)
+		}
+	}
+
+	_, err = s.Repo.CreateTag(name, commitHash, nil)
+	if err != nil {
+		if floating && previous != nil {
+			if restoreErr := s.Repo.SetReference(previous); restoreErr != nil {
+				s.log().Errorln(restoreErr)
+			}
 		}
+
+		return err
 	}
 
-	_, err := s.Repo.CreateTag(name, commitHash, nil)
+	s.createdTags = append(s.createdTags, createdTag{name: name, floating: floating, hash: commitHash, previous: previous})
 
-	return err
+	return nil
 }
 
-// Push pushes the changes to the remote repository.
-// It returns an error if the push operation fails.
-func (s *ScmGit) Push() error {
-	return s.Repo.Push(&git.PushOptions{
-		RemoteName: "origin",
-		RefSpecs: []config.RefSpec{
-			config.RefSpec("refs/tags/*:refs/tags/*"), // Push tags to the remote repository
-		},
-	})
+// Push pushes the tags created by Tag to every remote repository, one reference at a time.
+// Every reference is compared and swapped: a release tag is only created when the remote does not
+// have it on another commit, and on the first remote a floating tag is only moved when it still points
+// where it pointed before it moved locally. Floating tags are force pushed because they move between
+// commits, so they are not leased on mirrors. Release tags are pushed to a remote before its floating
+// tags, and pushing stops at the first rejected reference with the remaining references reported as
+// skipped, so floating tags never move on a remote that rejected the release they point to.
+// It returns the result for every reference and an error if any push fails, which wraps ErrTagConflict
+// when another release got there first. Canceling the context aborts the push in progress and skips
+// the remaining references.
+func (s *ScmGit) Push(ctx context.Context) ([]PushResult, error) {
+	results := make([]PushResult, 0, len(s.createdTags)*len(s.remotes()))
+
+	var pushErr error
+
+	tags := releaseTagsFirst(s.createdTags)
+
+	for _, remote := range s.remotes() {
+		var auth transport.AuthMethod
+
+		if pushErr == nil && len(tags) > 0 {
+			auth, pushErr = s.auth(remote)
+		}
+
+		for _, tag := range tags {
+			result, err := s.pushTag(ctx, remote, tag, auth, pushErr)
+			if err != nil {
+				pushErr = fmt.Errorf("%w: %s %s: %w", ErrPushRejected, remote, result.Ref, err)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, pushErr
+}
+
+// releaseTagsFirst returns the tags with the release tags before the floating tags, each in creation order.
+func releaseTagsFirst(tags []createdTag) []createdTag {
+	ordered := make([]createdTag, 0, len(tags))
+
+	for _, floating := range []bool{false, true} {
+		for _, tag := range tags {
+			if tag.floating == floating {
+				ordered = append(ordered, tag)
+			}
+		}
+	}
+
+	return ordered
+}
+
+// pushTag pushes a single tag to the remote, or reports it as skipped when an earlier push failed.
+// It returns the push error when the remote rejects the tag.
+func (s *ScmGit) pushTag(
+	ctx context.Context, remote string, tag createdTag, auth transport.AuthMethod, previousErr error,
+) (PushResult, error) {
+	refName := plumbing.NewTagReferenceName(tag.name)
+	result := PushResult{Remote: remote, Ref: refName.String(), Forced: tag.floating}
+
+	if previousErr != nil {
+		result.Status = PushStatusSkipped
+
+		return result, nil
+	}
+
+	current, err := s.checkRemoteTag(ctx, remote, tag, auth)
+	if err == nil {
+		err = s.Repo.Push(ctx, pushOptions(remote, tag, current, auth))
+	}
+
+	switch {
+	case err == nil:
+		result.Status = PushStatusPushed
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		result.Status = PushStatusUpToDate
+	default:
+		if !errors.Is(err, ErrTagConflict) && s.remoteTagMoved(ctx, remote, tag, auth) {
+			err = fmt.Errorf("%w: %w", ErrTagConflict, err)
+		}
+
+		result.Status = PushStatusRejected
+		result.Error = err.Error()
+
+		return result, err
+	}
+
+	return result, nil
+}
+
+// checkRemoteTag returns the hash of the tag on the remote, or the zero hash when the remote does not
+// have it. It returns ErrTagConflict when the remote tag is neither the pushed commit nor the value
+// expected before the push: absent for release tags, and the previous local value for floating tags.
+func (s *ScmGit) checkRemoteTag(
+	ctx context.Context, remote string, tag createdTag, auth transport.AuthMethod,
+) (plumbing.Hash, error) {
+	current, err := s.remoteTagHash(ctx, remote, tag.name, auth)
+	if err != nil {
+		return current, err
+	}
+
+	expected := plumbing.ZeroHash
+	if tag.floating && tag.previous != nil {
+		expected = tag.previous.Hash()
+	}
+
+	leased := !tag.floating || remote == s.remotes()[0]
+	if leased && current != expected && current != tag.hash {
+		return current, fmt.Errorf("%w: %s is %s on %s", ErrTagConflict, tag.name, current, remote)
+	}
+
+	return current, nil
+}
+
+// pushOptions returns the options pushing the tag, requiring the remote to still hold the current hash
+// so that the update is rejected when another push changed the tag in the meantime. When the remote does
+// not have the tag, the update is sent as a creation, which the remote rejects if the tag appeared.
+func pushOptions(remote string, tag createdTag, current plumbing.Hash, auth transport.AuthMethod) *git.PushOptions {
+	refName := plumbing.NewTagReferenceName(tag.name)
+
+	refSpec := config.RefSpec(refName.String() + ":" + refName.String())
+	if tag.floating {
+		refSpec = "+" + refSpec
+	}
+
+	options := &git.PushOptions{
+		RemoteName: remote,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Auth:       auth,
+	}
+
+	if !current.IsZero() {
+		options.RequireRemoteRefs = []config.RefSpec{config.RefSpec(current.String() + ":" + refName.String())}
+	}
+
+	return options
+}
+
+// remoteTagMoved reports whether the remote holds the tag on a commit other than the pushed one,
+// which means a push failed because another release updated the tag first.
+func (s *ScmGit) remoteTagMoved(ctx context.Context, remote string, tag createdTag, auth transport.AuthMethod) bool {
+	current, err := s.remoteTagHash(ctx, remote, tag.name, auth)
+
+	return err == nil && !current.IsZero() && current != tag.hash
+}
+
+// remoteTagHash returns the hash of the tag on the remote, or the zero hash when the remote does not have it.
+func (s *ScmGit) remoteTagHash(ctx context.Context, remote, name string, auth transport.AuthMethod) (plumbing.Hash, error) {
+	refs, err := s.Repo.ListRemote(ctx, remote, auth)
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return plumbing.ZeroHash, nil
+	}
+
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	refName := plumbing.NewTagReferenceName(name)
+
+	for _, ref := range refs {
+		if ref.Name() == refName {
+			return ref.Hash(), nil
+		}
+	}
+
+	return plumbing.ZeroHash, nil
+}
+
+// log returns the logger of the ScmGit.
+func (s *ScmGit) log() logrus.FieldLogger {
+	return orDefaultLogger(s.Logger)
+}
+
+// IsClean reports whether the working tree of the repository has no uncommitted changes.
+func (s *ScmGit) IsClean(ctx context.Context) (bool, error) {
+	err := s.Repo.PlainOpen(ctx, s.Path)
+	if err != nil {
+		return false, err
+	}
+
+	return s.Repo.IsClean()
+}
+
+// Rollback restores the tags created by Tag, newest first: moved floating tags point back to
+// their previous commit and new tags are deleted, while release tags that already existed are kept.
+// Every tag is attempted even if one fails.
+// It returns the result for every tag and an error if any tag cannot be restored.
+func (s *ScmGit) Rollback() ([]RollbackResult, error) {
+	results := make([]RollbackResult, 0, len(s.createdTags))
+
+	var rollbackErr error
+
+	for i := len(s.createdTags) - 1; i >= 0; i-- {
+		if s.createdTags[i].existing {
+			continue
+		}
+
+		result, err := s.rollbackTag(s.createdTags[i])
+		if err != nil && rollbackErr == nil {
+			rollbackErr = fmt.Errorf("%w: %s: %w", ErrRollbackFailed, result.Ref, err)
+		}
+
+		results = append(results, result)
+	}
+
+	s.createdTags = nil
+
+	return results, rollbackErr
+}
+
+// rollbackTag restores a single tag to the reference it replaced, or deletes it when it is new.
+func (s *ScmGit) rollbackTag(tag createdTag) (RollbackResult, error) {
+	result := RollbackResult{Ref: plumbing.NewTagReferenceName(tag.name).String()}
+
+	var err error
+
+	if tag.previous != nil {
+		result.Status = RollbackStatusRestored
+		result.Hash = tag.previous.Hash().String()
+		err = s.Repo.SetReference(tag.previous)
+	} else {
+		result.Status = RollbackStatusDeleted
+		err = s.Repo.DeleteTag(tag.name)
+	}
+
+	if err != nil {
+		result.Status = RollbackStatusFailed
+		result.Error = err.Error()
+	}
+
+	return result, err
+}
+
+// remotes returns the configured remotes, or origin when none is configured.
+func (s *ScmGit) remotes() []string {
+	if len(s.Remotes) == 0 {
+		return []string{defaultRemoteName}
+	}
+
+	return s.Remotes
+}
+
+// auth returns the authentication method for the named remote based on its URL.
+func (s *ScmGit) auth(remote string) (transport.AuthMethod, error) {
+	remoteURL, err := s.Repo.RemoteURL(remote)
+	if err != nil {
+		return nil, fmt.Errorf("remote %s: %w", remote, err)
+	}
+
+	return s.Credentials.AuthMethod(s.Logger, s.Path, remoteURL)
 }
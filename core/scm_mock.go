@@ -5,12 +5,15 @@
 package core
 
 import (
+	context "context"
 	reflect "reflect"
 
+	v4 "github.com/blang/semver/v4"
 	v5 "github.com/go-git/go-git/v5"
 	plumbing "github.com/go-git/go-git/v5/plumbing"
 	object "github.com/go-git/go-git/v5/plumbing/object"
 	storer "github.com/go-git/go-git/v5/plumbing/storer"
+	transport "github.com/go-git/go-git/v5/plumbing/transport"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -37,47 +40,108 @@ func (m *MockScm) EXPECT() *MockScmMockRecorder {
 	return m.recorder
 }
 
+// FetchTags mocks base method.
+func (m *MockScm) FetchTags(ctx context.Context, prune bool) ([]TagChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchTags", ctx, prune)
+	ret0, _ := ret[0].([]TagChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchTags indicates an expected call of FetchTags.
+func (mr *MockScmMockRecorder) FetchTags(ctx, prune interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchTags", reflect.TypeOf((*MockScm)(nil).FetchTags), ctx, prune)
+}
+
 // GetCommitLog mocks base method.
-func (m *MockScm) GetCommitLog() ([]*CommitLog, error) {
+func (m *MockScm) GetCommitLog(ctx context.Context) ([]*CommitLog, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCommitLog")
+	ret := m.ctrl.Call(m, "GetCommitLog", ctx)
 	ret0, _ := ret[0].([]*CommitLog)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCommitLog indicates an expected call of GetCommitLog.
-func (mr *MockScmMockRecorder) GetCommitLog() *gomock.Call {
+func (mr *MockScmMockRecorder) GetCommitLog(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitLog", reflect.TypeOf((*MockScm)(nil).GetCommitLog), ctx)
+}
+
+// GetTags mocks base method.
+func (m *MockScm) GetTags() ([]*v4.Version, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags")
+	ret0, _ := ret[0].([]*v4.Version)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockScmMockRecorder) GetTags() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommitLog", reflect.TypeOf((*MockScm)(nil).GetCommitLog))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockScm)(nil).GetTags))
+}
+
+// IsClean mocks base method.
+func (m *MockScm) IsClean(ctx context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsClean", ctx)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsClean indicates an expected call of IsClean.
+func (mr *MockScmMockRecorder) IsClean(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClean", reflect.TypeOf((*MockScm)(nil).IsClean), ctx)
 }
 
 // Push mocks base method.
-func (m *MockScm) Push() error {
+func (m *MockScm) Push(ctx context.Context) ([]PushResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Push")
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Push", ctx)
+	ret0, _ := ret[0].([]PushResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Push indicates an expected call of Push.
-func (mr *MockScmMockRecorder) Push() *gomock.Call {
+func (mr *MockScmMockRecorder) Push(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockScm)(nil).Push))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockScm)(nil).Push), ctx)
+}
+
+// Rollback mocks base method.
+func (m *MockScm) Rollback() ([]RollbackResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rollback")
+	ret0, _ := ret[0].([]RollbackResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rollback indicates an expected call of Rollback.
+func (mr *MockScmMockRecorder) Rollback() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockScm)(nil).Rollback))
 }
 
 // Tag mocks base method.
-func (m *MockScm) Tag(name, hash string, floating bool) error {
+func (m *MockScm) Tag(ctx context.Context, name, hash string, floating bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Tag", name, hash, floating)
+	ret := m.ctrl.Call(m, "Tag", ctx, name, hash, floating)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Tag indicates an expected call of Tag.
-func (mr *MockScmMockRecorder) Tag(name, hash, floating interface{}) *gomock.Call {
+func (mr *MockScmMockRecorder) Tag(ctx, name, hash, floating interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tag", reflect.TypeOf((*MockScm)(nil).Tag), name, hash, floating)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tag", reflect.TypeOf((*MockScm)(nil).Tag), ctx, name, hash, floating)
 }
 
 // MockGitRepo is a mock of GitRepo interface.
@@ -147,6 +211,20 @@ func (mr *MockGitRepoMockRecorder) DeleteTag(name interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockGitRepo)(nil).DeleteTag), name)
 }
 
+// Fetch mocks base method.
+func (m *MockGitRepo) Fetch(ctx context.Context, opts *v5.FetchOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, opts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockGitRepoMockRecorder) Fetch(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockGitRepo)(nil).Fetch), ctx, opts)
+}
+
 // Head mocks base method.
 func (m *MockGitRepo) Head() (*plumbing.Reference, error) {
 	m.ctrl.T.Helper()
@@ -162,6 +240,36 @@ func (mr *MockGitRepoMockRecorder) Head() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Head", reflect.TypeOf((*MockGitRepo)(nil).Head))
 }
 
+// IsClean mocks base method.
+func (m *MockGitRepo) IsClean() (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsClean")
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsClean indicates an expected call of IsClean.
+func (mr *MockGitRepoMockRecorder) IsClean() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsClean", reflect.TypeOf((*MockGitRepo)(nil).IsClean))
+}
+
+// ListRemote mocks base method.
+func (m *MockGitRepo) ListRemote(ctx context.Context, name string, auth transport.AuthMethod) ([]*plumbing.Reference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRemote", ctx, name, auth)
+	ret0, _ := ret[0].([]*plumbing.Reference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRemote indicates an expected call of ListRemote.
+func (mr *MockGitRepoMockRecorder) ListRemote(ctx, name, auth interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRemote", reflect.TypeOf((*MockGitRepo)(nil).ListRemote), ctx, name, auth)
+}
+
 // Log mocks base method.
 func (m *MockGitRepo) Log(arg0 *v5.LogOptions) (object.CommitIter, error) {
 	m.ctrl.T.Helper()
@@ -178,31 +286,104 @@ func (mr *MockGitRepoMockRecorder) Log(arg0 interface{}) *gomock.Call {
 }
 
 // PlainOpen mocks base method.
-func (m *MockGitRepo) PlainOpen(arg0 string) error {
+func (m *MockGitRepo) PlainOpen(ctx context.Context, path string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "PlainOpen", arg0)
+	ret := m.ctrl.Call(m, "PlainOpen", ctx, path)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // PlainOpen indicates an expected call of PlainOpen.
-func (mr *MockGitRepoMockRecorder) PlainOpen(arg0 interface{}) *gomock.Call {
+func (mr *MockGitRepoMockRecorder) PlainOpen(ctx, path interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlainOpen", reflect.TypeOf((*MockGitRepo)(nil).PlainOpen), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlainOpen", reflect.TypeOf((*MockGitRepo)(nil).PlainOpen), ctx, path)
 }
 
 // Push mocks base method.
-func (m *MockGitRepo) Push(opts *v5.PushOptions) error {
+func (m *MockGitRepo) Push(ctx context.Context, opts *v5.PushOptions) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Push", opts)
+	ret := m.ctrl.Call(m, "Push", ctx, opts)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Push indicates an expected call of Push.
-func (mr *MockGitRepoMockRecorder) Push(opts interface{}) *gomock.Call {
+func (mr *MockGitRepoMockRecorder) Push(ctx, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockGitRepo)(nil).Push), ctx, opts)
+}
+
+// Reference mocks base method.
+func (m *MockGitRepo) Reference(name plumbing.ReferenceName) (*plumbing.Reference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reference", name)
+	ret0, _ := ret[0].(*plumbing.Reference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reference indicates an expected call of Reference.
+func (mr *MockGitRepoMockRecorder) Reference(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reference", reflect.TypeOf((*MockGitRepo)(nil).Reference), name)
+}
+
+// RemoteURL mocks base method.
+func (m *MockGitRepo) RemoteURL(name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoteURL", name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoteURL indicates an expected call of RemoteURL.
+func (mr *MockGitRepoMockRecorder) RemoteURL(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoteURL", reflect.TypeOf((*MockGitRepo)(nil).RemoteURL), name)
+}
+
+// SetReference mocks base method.
+func (m *MockGitRepo) SetReference(ref *plumbing.Reference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReference", ref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReference indicates an expected call of SetReference.
+func (mr *MockGitRepoMockRecorder) SetReference(ref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReference", reflect.TypeOf((*MockGitRepo)(nil).SetReference), ref)
+}
+
+// SetShallow mocks base method.
+func (m *MockGitRepo) SetShallow(hashes []plumbing.Hash) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShallow", hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShallow indicates an expected call of SetShallow.
+func (mr *MockGitRepoMockRecorder) SetShallow(hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShallow", reflect.TypeOf((*MockGitRepo)(nil).SetShallow), hashes)
+}
+
+// Shallow mocks base method.
+func (m *MockGitRepo) Shallow() ([]plumbing.Hash, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Shallow")
+	ret0, _ := ret[0].([]plumbing.Hash)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Shallow indicates an expected call of Shallow.
+func (mr *MockGitRepoMockRecorder) Shallow() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Push", reflect.TypeOf((*MockGitRepo)(nil).Push), opts)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shallow", reflect.TypeOf((*MockGitRepo)(nil).Shallow))
 }
 
 // Tags mocks base method.
@@ -1,17 +1,24 @@
 package core_test
 
 import (
+	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
 	"github.com/golang/mock/gomock"
 	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var errExpectedError = errors.New("expected error")
@@ -28,7 +35,7 @@ func TestScmGit_GetCommitLog(t *testing.T) {
 	mockReferenceIter := core.NewMockReferenceIter(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().PlainOpen(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(nil)
 	mockRepo.EXPECT().Head().Return(plumbing.NewHashReference("refs/branches/main",
 		plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b")), nil)
 
@@ -58,7 +65,7 @@ func TestScmGit_GetCommitLog(t *testing.T) {
 	}
 
 	// Call the method under test
-	commitLogs, err := scm.GetCommitLog()
+	commitLogs, err := scm.GetCommitLog(context.Background())
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -92,7 +99,7 @@ func TestScmGit_GetCommitLogShouldFailIfRepositoryCannotBeOpened(t *testing.T) {
 	mockRepo := core.NewMockGitRepo(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().PlainOpen(gomock.Any()).Return(errExpectedError)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(errExpectedError)
 
 	// Create the ScmGit instance with the mock Repo
 	scm := core.ScmGit{
@@ -101,7 +108,7 @@ func TestScmGit_GetCommitLogShouldFailIfRepositoryCannotBeOpened(t *testing.T) {
 	}
 
 	// Call the method under test
-	commitLogs, err := scm.GetCommitLog()
+	commitLogs, err := scm.GetCommitLog(context.Background())
 
 	// Assert the results
 	assert.Nil(t, commitLogs)
@@ -118,7 +125,7 @@ func TestScmGit_GetCommitLogShouldFailIfHeadNotFound(t *testing.T) {
 	mockRepo := core.NewMockGitRepo(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().PlainOpen(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(nil)
 	mockRepo.EXPECT().Head().Return(nil, errExpectedError)
 
 	// Create the ScmGit instance with the mock Repo
@@ -128,7 +135,7 @@ func TestScmGit_GetCommitLogShouldFailIfHeadNotFound(t *testing.T) {
 	}
 
 	// Call the method under test
-	commitLogs, err := scm.GetCommitLog()
+	commitLogs, err := scm.GetCommitLog(context.Background())
 
 	// Assert the results
 	assert.Nil(t, commitLogs)
@@ -145,7 +152,7 @@ func TestScmGit_GetCommitLogShouldFailIfCommitLogCannotFound(t *testing.T) {
 	mockRepo := core.NewMockGitRepo(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().PlainOpen(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(nil)
 	mockRepo.EXPECT().Head().Return(plumbing.NewHashReference("refs/branches/main",
 		plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b")), nil)
 
@@ -158,7 +165,7 @@ func TestScmGit_GetCommitLogShouldFailIfCommitLogCannotFound(t *testing.T) {
 	}
 
 	// Call the method under test
-	commitLogs, err := scm.GetCommitLog()
+	commitLogs, err := scm.GetCommitLog(context.Background())
 
 	assert.Nil(t, commitLogs)
 	assert.Equal(t, errExpectedError, err)
@@ -176,7 +183,7 @@ func TestScmGit_GetCommitLogShouldNotFailIfTagsOrSemver(t *testing.T) {
 	mockReferenceIter := core.NewMockReferenceIter(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().PlainOpen(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(nil)
 	mockRepo.EXPECT().Head().Return(plumbing.NewHashReference("refs/branches/main",
 		plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b")), nil)
 
@@ -206,7 +213,7 @@ func TestScmGit_GetCommitLogShouldNotFailIfTagsOrSemver(t *testing.T) {
 	}
 
 	// Call the method under test
-	commitLogs, err := scm.GetCommitLog()
+	commitLogs, err := scm.GetCommitLog(context.Background())
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -252,6 +259,7 @@ func TestScmGit_TagShouldNotFailIfFloatingTagAlreadyExist(t *testing.T) {
 	mockRepo.EXPECT().CreateTag("v1", plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b"), nil).Return(nil, nil)
 	mockRepo.EXPECT().CreateTag("v1", plumbing.NewHash("d56f2faecd0a2a1d666c19f813c9a8f573fc121b"), nil).Return(nil, nil)
 	mockRepo.EXPECT().DeleteTag("v1").Return(nil).Times(2)
+	mockRepo.EXPECT().Reference(plumbing.NewTagReferenceName("v1")).Return(nil, plumbing.ErrReferenceNotFound).Times(2)
 
 	// Create the ScmGit instance with the mock Repo
 	scm := core.ScmGit{
@@ -260,12 +268,12 @@ func TestScmGit_TagShouldNotFailIfFloatingTagAlreadyExist(t *testing.T) {
 	}
 
 	// Call the method under test
-	err := scm.Tag("v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true)
+	err := scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true)
 
 	// Assert the results
 	assert.NoError(t, err)
 
-	err = scm.Tag("v1", "d56f2faecd0a2a1d666c19f813c9a8f573fc121b", true)
+	err = scm.Tag(context.Background(), "v1", "d56f2faecd0a2a1d666c19f813c9a8f573fc121b", true)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -282,6 +290,7 @@ func TestScmGit_TagShouldNotUseFloatingTag(t *testing.T) {
 
 	// Set up expectations on the mock Repo
 	mockRepo.EXPECT().CreateTag("v1", plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b"), nil).Return(nil, nil)
+	mockRepo.EXPECT().Reference(gomock.Any()).Return(nil, plumbing.ErrReferenceNotFound)
 
 	// Create the ScmGit instance with the mock Repo
 	scm := core.ScmGit{
@@ -290,7 +299,7 @@ func TestScmGit_TagShouldNotUseFloatingTag(t *testing.T) {
 	}
 
 	// Call the method under test
-	err := scm.Tag("v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false)
+	err := scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -308,6 +317,7 @@ func TestScmGit_TagShouldNotFailIfFloatingTagDeletionFails(t *testing.T) {
 	// Set up expectations on the mock Repo
 	mockRepo.EXPECT().CreateTag("v1", plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b"), nil).Return(nil, nil)
 	mockRepo.EXPECT().DeleteTag("v1").Return(errExpectedError)
+	mockRepo.EXPECT().Reference(gomock.Any()).Return(nil, plumbing.ErrReferenceNotFound)
 
 	// Create the ScmGit instance with the mock Repo
 	scm := core.ScmGit{
@@ -316,7 +326,7 @@ func TestScmGit_TagShouldNotFailIfFloatingTagDeletionFails(t *testing.T) {
 	}
 
 	// Call the method under test
-	err := scm.Tag("v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true)
+	err := scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -332,7 +342,14 @@ func TestScmGit_Push(t *testing.T) {
 	mockRepo := core.NewMockGitRepo(ctrl)
 
 	// Set up expectations on the mock Repo
-	mockRepo.EXPECT().Push(gomock.Any()).Return(nil)
+	mockRepo.EXPECT().CreateTag("v1.0.0", gomock.Any(), nil).Return(nil, nil)
+	mockRepo.EXPECT().Reference(gomock.Any()).Return(nil, plumbing.ErrReferenceNotFound)
+	mockRepo.EXPECT().RemoteURL("origin").Return("/path/to/remote.git", nil)
+	mockRepo.EXPECT().ListRemote(gomock.Any(), "origin", nil).Return(nil, nil)
+	mockRepo.EXPECT().Push(gomock.Any(), &git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{"refs/tags/v1.0.0:refs/tags/v1.0.0"},
+	}).Return(nil)
 
 	// Create the ScmGit instance with the mock Repo
 	scm := core.ScmGit{
@@ -340,9 +357,731 @@ func TestScmGit_Push(t *testing.T) {
 		Repo: mockRepo,
 	}
 
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.0", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false))
+
 	// Call the method under test
-	err := scm.Push()
+	results, err := scm.Push(context.Background())
 
 	// Assert the results
 	assert.NoError(t, err)
+	assert.Equal(t, []core.PushResult{{Remote: "origin", Ref: "refs/tags/v1.0.0", Status: core.PushStatusPushed}}, results)
+}
+
+func TestScmGit_PushShouldNotPushWithoutCreatedTags(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().Push(gomock.Any(), gomock.Any()).Times(0)
+
+	scm := core.ScmGit{
+		Path: "/path/to/repo",
+		Repo: mockRepo,
+	}
+
+	results, err := scm.Push(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestScmGit_PushShouldStopAtFirstRejectedReference(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().CreateTag(gomock.Any(), gomock.Any(), nil).Return(nil, nil).Times(2)
+	mockRepo.EXPECT().DeleteTag("v1").Return(nil)
+	mockRepo.EXPECT().Reference(gomock.Any()).Return(nil, plumbing.ErrReferenceNotFound).Times(2)
+	mockRepo.EXPECT().RemoteURL("origin").Return("file:///path/to/remote.git", nil)
+	mockRepo.EXPECT().Push(gomock.Any(), gomock.Any()).Return(errExpectedError).Times(1)
+	mockRepo.EXPECT().ListRemote(gomock.Any(), "origin", nil).Return(nil, nil).Times(2)
+
+	scm := core.ScmGit{
+		Path: "/path/to/repo",
+		Repo: mockRepo,
+	}
+
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.0", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false))
+	require.NoError(t, scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true))
+
+	results, err := scm.Push(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrPushRejected)
+	assert.ErrorIs(t, err, errExpectedError)
+	assert.Equal(t, []core.PushResult{
+		{Remote: "origin", Ref: "refs/tags/v1.0.0", Status: core.PushStatusRejected, Error: errExpectedError.Error()},
+		{Remote: "origin", Ref: "refs/tags/v1", Forced: true, Status: core.PushStatusSkipped},
+	}, results)
+}
+
+func TestScmGit_PushShouldOnlyPublishTagsCreatedInThisRun(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	gittest.CommitFile(t, source, "feat: initial commit")
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	first := gittest.CommitFile(t, clone, "feat: first feature")
+	_, err = clone.CreateTag("someone-elses-tag", first, nil)
+	require.NoError(t, err)
+
+	// First release creates v1.0.0 and the floating v1.
+	scm := core.NewScmGitBuilder().SetPath(clonePath).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.0", first.String(), false))
+	require.NoError(t, scm.Tag(context.Background(), "v1", first.String(), true))
+
+	results, err := scm.Push(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.PushResult{
+		{Remote: "origin", Ref: "refs/tags/v1.0.0", Status: core.PushStatusPushed},
+		{Remote: "origin", Ref: "refs/tags/v1", Forced: true, Status: core.PushStatusPushed},
+	}, results)
+
+	// Second release moves v1, which the remote only accepts as a forced update.
+	second := gittest.CommitFile(t, clone, "fix: first fix")
+	scm = core.NewScmGitBuilder().SetPath(clonePath).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.1", second.String(), false))
+	require.NoError(t, scm.Tag(context.Background(), "v1", second.String(), true))
+
+	results, err = scm.Push(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.PushResult{
+		{Remote: "origin", Ref: "refs/tags/v1.0.1", Status: core.PushStatusPushed},
+		{Remote: "origin", Ref: "refs/tags/v1", Forced: true, Status: core.PushStatusPushed},
+	}, results)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	for name, hash := range map[string]plumbing.Hash{"v1.0.0": first, "v1.0.1": second, "v1": second} {
+		ref, errTag := remote.Tag(name)
+		require.NoError(t, errTag, name)
+		assert.Equal(t, hash, ref.Hash(), name)
+	}
+
+	_, err = remote.Tag("someone-elses-tag")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestScmGit_GetCommitLogShouldFailOnShallowCloneWithoutTags(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	tagged := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", tagged, nil)
+	require.NoError(t, err)
+	gittest.CommitFile(t, source, "fix: first fix")
+	gittest.CommitFile(t, source, "fix: second fix")
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	_, err = git.PlainClone(clonePath, false, &git.CloneOptions{URL: "file://" + gittest.CloneBare(t, sourcePath), Depth: 1})
+	require.NoError(t, err)
+
+	commitLogs, err := core.NewScmGitBuilder().SetPath(clonePath).Build().GetCommitLog(context.Background())
+
+	assert.Nil(t, commitLogs)
+	assert.ErrorIs(t, err, core.ErrShallowRepository)
+}
+
+func TestScmGit_GetCommitLogShouldDeepenShallowCloneUntilTagIsReached(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	tagged := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", tagged, nil)
+	require.NoError(t, err)
+	gittest.CommitFile(t, source, "fix: first fix")
+	gittest.CommitFile(t, source, "fix: second fix")
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	_, err = git.PlainClone(clonePath, false, &git.CloneOptions{URL: "file://" + gittest.CloneBare(t, sourcePath), Depth: 1})
+	require.NoError(t, err)
+
+	commitLogs, err := core.NewScmGitBuilder().SetPath(clonePath).SetDeepen(true).Build().GetCommitLog(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, commitLogs, 3)
+	assert.Equal(t, tagged.String(), commitLogs[2].Hash)
+	assert.Equal(t, []*semver.Version{{Major: 1, Minor: 0, Patch: 0}}, commitLogs[2].Tags)
+	assert.NoFileExists(t, filepath.Join(clonePath, ".git", "shallow"), "the complete history is not shallow")
+}
+
+func TestScmGit_GetCommitLogShouldKeepOnlyNewBoundaryAfterDeepening(t *testing.T) {
+	t.Parallel()
+
+	const commitsBeforeTag, commitsAfterTag = 60, 30 // The root is deeper than the first deepening from the tag
+
+	sourcePath, source := gittest.InitRepository(t)
+	for range commitsBeforeTag {
+		gittest.CommitFile(t, source, "chore: before the release")
+	}
+
+	tagged := gittest.CommitFile(t, source, "feat: release")
+	_, err := source.CreateTag("v1.0.0", tagged, nil)
+	require.NoError(t, err)
+
+	for range commitsAfterTag {
+		gittest.CommitFile(t, source, "fix: after the release")
+	}
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	_, err = git.PlainClone(clonePath, false, &git.CloneOptions{URL: "file://" + gittest.CloneBare(t, sourcePath), Depth: 1})
+	require.NoError(t, err)
+
+	commitLogs, err := core.NewScmGitBuilder().SetPath(clonePath).SetDeepen(true).Build().GetCommitLog(context.Background())
+
+	require.NoError(t, err)
+	require.Less(t, len(commitLogs), commitsBeforeTag+1+commitsAfterTag)
+	assert.Equal(t, tagged.String(), commitLogs[commitsAfterTag].Hash)
+
+	clone, err := git.PlainOpen(clonePath)
+	require.NoError(t, err)
+
+	shallows, err := clone.Storer.Shallow()
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{plumbing.NewHash(commitLogs[len(commitLogs)-1].Hash)}, shallows,
+		"the boundary of the first clone must not stay in .git/shallow")
+}
+
+func TestScmGit_GetCommitLogShouldFailIfDeepeningFails(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockCommitIter := core.NewMockCommitIter(ctrl)
+
+	head := plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b")
+	commit := &object.Commit{Hash: head, Message: "fix: shallow commit"}
+
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().Head().Return(plumbing.NewHashReference("refs/heads/main", head), nil)
+	mockRepo.EXPECT().Log(&git.LogOptions{From: head}).Return(mockCommitIter, nil)
+	mockCommitIter.EXPECT().Next().Return(commit, nil)
+	mockCommitIter.EXPECT().Next().Return(nil, plumbing.ErrObjectNotFound)
+	mockReferenceIter := core.NewMockReferenceIter(ctrl)
+	mockReferenceIter.EXPECT().Next().Return(nil, nil)
+	mockRepo.EXPECT().Tags().Return(mockReferenceIter, nil)
+	mockRepo.EXPECT().Shallow().Return([]plumbing.Hash{head}, nil)
+	mockRepo.EXPECT().RemoteURL("origin").Return("https://example.com/repo.git", nil)
+	mockRepo.EXPECT().Fetch(gomock.Any(), gomock.Any()).Return(errExpectedError)
+
+	scm := core.ScmGit{
+		Path:        "/path/to/repo",
+		Repo:        mockRepo,
+		Deepen:      true,
+		Credentials: core.Credentials{Token: "secret"},
+	}
+
+	commitLogs, err := scm.GetCommitLog(context.Background())
+
+	assert.Nil(t, commitLogs)
+	assert.Equal(t, errExpectedError, err)
+}
+
+func TestScmGit_GetCommitLogShouldSetBranchNameOnHead(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	gittest.CommitFile(t, repo, "feat: initial commit")
+	gittest.CommitFile(t, repo, "fix: first fix")
+
+	commitLogs, err := core.NewScmGitBuilder().SetPath(path).Build().GetCommitLog(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, commitLogs, 2)
+	assert.Equal(t, "master", commitLogs[0].BranchName)
+	assert.Empty(t, commitLogs[1].BranchName)
+}
+
+func TestScmGit_GetTagsShouldReturnEverySemanticVersionTag(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	first := gittest.CommitFile(t, repo, "feat: initial commit")
+	second := gittest.CommitFile(t, repo, "feat: second commit")
+
+	for name, hash := range map[string]plumbing.Hash{"v1.0.0": first, "v1.1.0": second, "latest": second, "1.2.0-rc.1": second} {
+		_, err := repo.CreateTag(name, hash, nil)
+		require.NoError(t, err)
+	}
+
+	scm := core.NewScmGitBuilder().SetPath(path).Build()
+	_, err := scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+
+	tags, err := scm.GetTags()
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*semver.Version{
+		{Major: 1, Minor: 0, Patch: 0},
+		{Major: 1, Minor: 1, Patch: 0},
+		{Major: 1, Minor: 2, Patch: 0, Pre: []semver.PRVersion{{VersionStr: "rc"}, {VersionNum: 1, IsNum: true}}},
+	}, tags)
+}
+
+func TestScmGit_PushShouldPublishToEveryRemote(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	gittest.CommitFile(t, source, "feat: initial commit")
+
+	primaryPath := gittest.CloneBare(t, sourcePath)
+	mirrorPath := gittest.CloneBare(t, sourcePath)
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: "file://" + primaryPath})
+	require.NoError(t, err)
+
+	_, err = clone.CreateRemote(&config.RemoteConfig{Name: "mirror", URLs: []string{mirrorPath}})
+	require.NoError(t, err)
+
+	hash := gittest.CommitFile(t, clone, "feat: first feature")
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).SetRemotes([]string{"origin", "mirror"}).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v0.1.0", hash.String(), false))
+
+	results, err := scm.Push(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.PushResult{
+		{Remote: "origin", Ref: "refs/tags/v0.1.0", Status: core.PushStatusPushed},
+		{Remote: "mirror", Ref: "refs/tags/v0.1.0", Status: core.PushStatusPushed},
+	}, results)
+
+	for _, remotePath := range []string{primaryPath, mirrorPath} {
+		remote, errOpen := git.PlainOpen(remotePath)
+		require.NoError(t, errOpen)
+
+		ref, errTag := remote.Tag("v0.1.0")
+		require.NoError(t, errTag, remotePath)
+		assert.Equal(t, hash, ref.Hash(), remotePath)
+	}
+}
+
+func TestScmGit_PushShouldNotMoveFloatingTagsOnRemoteRejectingTheRelease(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	first := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v0.1.0", first, nil)
+	require.NoError(t, err)
+	_, err = source.CreateTag("v0", first, nil)
+	require.NoError(t, err)
+
+	primaryPath := gittest.CloneBare(t, sourcePath)
+	mirrorPath := gittest.CloneBare(t, sourcePath)
+
+	// Another release took v0.1.1 on the mirror only.
+	mirror, err := git.PlainOpen(mirrorPath)
+	require.NoError(t, err)
+	_, err = mirror.CreateTag("v0.1.1", first, nil)
+	require.NoError(t, err)
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: "file://" + primaryPath})
+	require.NoError(t, err)
+
+	_, err = clone.CreateRemote(&config.RemoteConfig{Name: "mirror", URLs: []string{mirrorPath}})
+	require.NoError(t, err)
+
+	second := gittest.CommitFile(t, clone, "fix: first fix")
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).SetRemotes([]string{"origin", "mirror"}).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v0", second.String(), true))
+	require.NoError(t, scm.Tag(context.Background(), "v0.1.1", second.String(), false))
+
+	results, err := scm.Push(context.Background())
+
+	require.ErrorIs(t, err, core.ErrTagConflict)
+	require.Len(t, results, 4)
+	assert.Equal(t, []string{"refs/tags/v0.1.1", "refs/tags/v0"}, []string{results[0].Ref, results[1].Ref})
+	assert.Equal(t, core.PushStatusPushed, results[1].Status)
+	assert.Equal(t, "refs/tags/v0.1.1", results[2].Ref)
+	assert.Equal(t, core.PushStatusRejected, results[2].Status)
+	assert.Equal(t, core.PushResult{Remote: "mirror", Ref: "refs/tags/v0", Forced: true, Status: core.PushStatusSkipped}, results[3])
+
+	ref, err := mirror.Tag("v0")
+	require.NoError(t, err)
+	assert.Equal(t, first, ref.Hash())
+}
+
+func TestScmGit_PushShouldFailForUnknownRemote(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	hash := gittest.CommitFile(t, repo, "feat: initial commit")
+
+	scm := core.NewScmGitBuilder().SetPath(path).SetRemotes([]string{"missing"}).Build()
+	_, err := scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v0.1.0", hash.String(), false))
+
+	results, err := scm.Push(context.Background())
+
+	assert.ErrorIs(t, err, git.ErrRemoteNotFound)
+	assert.Equal(t, []core.PushResult{{Remote: "missing", Ref: "refs/tags/v0.1.0", Status: core.PushStatusSkipped}}, results)
+}
+
+func TestScmGit_RollbackShouldRestoreTagsAfterFailedPush(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	first := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1.0.0", first, nil)
+	require.NoError(t, err)
+	_, err = source.CreateTag("v1", first, nil)
+	require.NoError(t, err)
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	second := gittest.CommitFile(t, clone, "fix: first fix")
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v1", second.String(), true))
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.1", second.String(), false))
+
+	// The remote disappears, so the push fails after the tags were created locally.
+	require.NoError(t, os.RemoveAll(remotePath))
+
+	_, err = scm.Push(context.Background())
+	require.ErrorIs(t, err, core.ErrPushRejected)
+
+	results, err := scm.Rollback()
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.RollbackResult{
+		{Ref: "refs/tags/v1.0.1", Status: core.RollbackStatusDeleted},
+		{Ref: "refs/tags/v1", Status: core.RollbackStatusRestored, Hash: first.String()},
+	}, results)
+
+	ref, err := clone.Tag("v1")
+	require.NoError(t, err)
+	assert.Equal(t, first, ref.Hash())
+
+	_, err = clone.Tag("v1.0.1")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+
+	results, err = scm.Rollback()
+
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestScmGit_RollbackShouldReportTagsThatCannotBeRestored(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	previous := plumbing.NewHashReference(plumbing.NewTagReferenceName("v1"),
+		plumbing.NewHash("d56f2faecd0a2a1d666c19f813c9a8f573fc121b"))
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().Reference(plumbing.NewTagReferenceName("v1")).Return(previous, nil)
+	mockRepo.EXPECT().Reference(plumbing.NewTagReferenceName("v1.0.1")).Return(nil, plumbing.ErrReferenceNotFound)
+	mockRepo.EXPECT().DeleteTag("v1").Return(nil)
+	mockRepo.EXPECT().CreateTag(gomock.Any(), gomock.Any(), nil).Return(nil, nil).Times(2)
+	mockRepo.EXPECT().DeleteTag("v1.0.1").Return(nil)
+	mockRepo.EXPECT().SetReference(previous).Return(errExpectedError)
+
+	scm := core.ScmGit{
+		Path: "/path/to/repo",
+		Repo: mockRepo,
+	}
+
+	require.NoError(t, scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true))
+	require.NoError(t, scm.Tag(context.Background(), "v1.0.1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false))
+
+	results, err := scm.Rollback()
+
+	assert.ErrorIs(t, err, core.ErrRollbackFailed)
+	assert.ErrorIs(t, err, errExpectedError)
+	assert.Equal(t, []core.RollbackResult{
+		{Ref: "refs/tags/v1.0.1", Status: core.RollbackStatusDeleted},
+		{
+			Ref:    "refs/tags/v1",
+			Status: core.RollbackStatusFailed,
+			Hash:   "d56f2faecd0a2a1d666c19f813c9a8f573fc121b",
+			Error:  errExpectedError.Error(),
+		},
+	}, results)
+}
+
+func TestScmGit_TagShouldRestoreFloatingTagIfCreationFails(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	previous := plumbing.NewHashReference(plumbing.NewTagReferenceName("v1"),
+		plumbing.NewHash("d56f2faecd0a2a1d666c19f813c9a8f573fc121b"))
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().Reference(plumbing.NewTagReferenceName("v1")).Return(previous, nil)
+	mockRepo.EXPECT().DeleteTag("v1").Return(nil)
+	mockRepo.EXPECT().CreateTag("v1", gomock.Any(), nil).Return(nil, errExpectedError)
+	mockRepo.EXPECT().SetReference(previous).Return(nil)
+
+	scm := core.ScmGit{
+		Path: "/path/to/repo",
+		Repo: mockRepo,
+	}
+
+	err := scm.Tag(context.Background(), "v1", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", true)
+
+	assert.ErrorIs(t, err, errExpectedError)
+}
+
+func TestScmGit_TagShouldDetectReleaseTagOnAnotherCommit(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	existing := plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"),
+		plumbing.NewHash("d56f2faecd0a2a1d666c19f813c9a8f573fc121b"))
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().Reference(plumbing.NewTagReferenceName("v1.0.0")).Return(existing, nil).Times(2)
+	mockRepo.EXPECT().CreateTag(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	scm := core.ScmGit{
+		Path: "/path/to/repo",
+		Repo: mockRepo,
+	}
+
+	err := scm.Tag(context.Background(), "v1.0.0", "e574dfaecd0a2a1d666c19f813c9a8f573fc121b", false)
+
+	assert.ErrorIs(t, err, core.ErrTagConflict)
+
+	err = scm.Tag(context.Background(), "v1.0.0", "d56f2faecd0a2a1d666c19f813c9a8f573fc121b", false)
+
+	assert.ErrorIs(t, err, git.ErrTagExists)
+}
+
+func TestScmGit_PushShouldNotOverwriteReleaseTagOnRemote(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	gittest.CommitFile(t, source, "feat: initial commit")
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	// Another pipeline released v1.1.0 on the parent, so moving the tag would be a fast-forward.
+	parent := gittest.CommitFile(t, clone, "feat: first feature")
+	_, err = clone.CreateTag("v1.1.0", parent, nil)
+	require.NoError(t, err)
+	require.NoError(t, clone.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"refs/tags/v1.1.0:refs/tags/v1.1.0"}}))
+	require.NoError(t, clone.DeleteTag("v1.1.0"))
+
+	child := gittest.CommitFile(t, clone, "feat: second feature")
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v1.1.0", child.String(), false))
+
+	results, err := scm.Push(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrTagConflict)
+	assert.Equal(t, core.PushStatusRejected, results[0].Status)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	ref, err := remote.Tag("v1.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, parent, ref.Hash())
+}
+
+func TestScmGit_PushShouldNotMoveFloatingTagChangedOnRemote(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	first := gittest.CommitFile(t, source, "feat: initial commit")
+	_, err := source.CreateTag("v1", first, nil)
+	require.NoError(t, err)
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	// Another pipeline moved v1 after this clone fetched it.
+	other, err := git.PlainClone(filepath.Join(t.TempDir(), "other"), false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	second := gittest.CommitFile(t, other, "feat: released elsewhere")
+	require.NoError(t, other.DeleteTag("v1"))
+	_, err = other.CreateTag("v1", second, nil)
+	require.NoError(t, err)
+	require.NoError(t, other.Push(&git.PushOptions{RefSpecs: []config.RefSpec{"+refs/tags/v1:refs/tags/v1"}}))
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+
+	third := gittest.CommitFile(t, clone, "fix: local fix")
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).Build()
+	_, err = scm.GetCommitLog(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, scm.Tag(context.Background(), "v1", third.String(), true))
+
+	_, err = scm.Push(context.Background())
+
+	assert.ErrorIs(t, err, core.ErrTagConflict)
+
+	ref, err := remote.Tag("v1")
+	require.NoError(t, err)
+	assert.Equal(t, second, ref.Hash())
+}
+
+func TestScmGit_FetchTagsShouldReportAndPruneChangedTags(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+	first := gittest.CommitFile(t, source, "feat: initial commit")
+	second := gittest.CommitFile(t, source, "feat: second feature")
+	_, err := source.CreateTag("v1", first, nil)
+	require.NoError(t, err)
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(clonePath, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+	_, err = clone.CreateTag("v0.9.0", first, nil)
+	require.NoError(t, err)
+
+	remote, err := git.PlainOpen(remotePath)
+	require.NoError(t, err)
+	_, err = remote.CreateTag("v1.0.0", second, nil)
+	require.NoError(t, err)
+	require.NoError(t, remote.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1"), second)))
+
+	scm := core.NewScmGitBuilder().SetPath(clonePath).Build()
+
+	changes, err := scm.FetchTags(context.Background(), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.TagChange{
+		{Ref: "refs/tags/v1", Status: core.TagChangeUpdated, Hash: second.String(), Previous: first.String()},
+		{Ref: "refs/tags/v1.0.0", Status: core.TagChangeAdded, Hash: second.String()},
+	}, changes)
+
+	changes, err = scm.FetchTags(context.Background(), true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []core.TagChange{
+		{Ref: "refs/tags/v0.9.0", Status: core.TagChangePruned, Previous: first.String()},
+	}, changes)
+
+	_, err = clone.Tag("v0.9.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
+
+	changes, err = scm.FetchTags(context.Background(), true)
+
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestScmGit_FetchTagsShouldFetchFromFirstRemote(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	defer ctrl.Finish()
+
+	tag := plumbing.NewHashReference(plumbing.NewTagReferenceName("v1.0.0"),
+		plumbing.NewHash("e574dfaecd0a2a1d666c19f813c9a8f573fc121b"))
+
+	mockRepo := core.NewMockGitRepo(ctrl)
+	mockRepo.EXPECT().PlainOpen(gomock.Any(), "/path/to/repo").Return(nil)
+	mockRepo.EXPECT().RemoteURL("upstream").Return("/path/to/remote.git", nil)
+	gomock.InOrder(
+		mockRepo.EXPECT().Tags().Return(storer.NewReferenceSliceIter(nil), nil),
+		mockRepo.EXPECT().Fetch(gomock.Any(), &git.FetchOptions{
+			RemoteName: "upstream",
+			RefSpecs:   []config.RefSpec{"+refs/tags/*:refs/tags/*"},
+			Tags:       git.NoTags,
+			Prune:      true,
+		}).Return(nil),
+		mockRepo.EXPECT().Tags().Return(storer.NewReferenceSliceIter([]*plumbing.Reference{tag}), nil),
+	)
+
+	scm := core.NewScmGitBuilder().SetPath("/path/to/repo").SetRepo(mockRepo).SetRemotes([]string{"upstream", "mirror"}).Build()
+
+	changes, err := scm.FetchTags(context.Background(), true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []core.TagChange{{Ref: "refs/tags/v1.0.0", Status: core.TagChangeAdded, Hash: tag.Hash().String()}}, changes)
+}
+
+func TestScmGit_IsCleanShouldIgnoreUntrackedFiles(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	gittest.CommitFile(t, repo, "feat: initial commit")
+
+	scm := core.NewScmGitBuilder().SetPath(path).Build()
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "untracked.txt"), []byte("untracked"), 0o600))
+
+	clean, err := scm.IsClean(context.Background())
+	require.NoError(t, err)
+	assert.True(t, clean)
+
+	require.NoError(t, os.WriteFile(filepath.Join(path, "file.txt"), []byte("modified"), 0o600))
+
+	clean, err = scm.IsClean(context.Background())
+	require.NoError(t, err)
+	assert.False(t, clean)
+}
+
+func TestScmGit_ShouldStopIfContextIsCanceled(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+	head := gittest.CommitFile(t, repo, "feat: initial commit")
+
+	scm := core.NewScmGitBuilder().SetPath(path).Build()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := scm.GetCommitLog(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = scm.Tag(ctx, "v0.1.0", head.String(), false)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = repo.Tag("v0.1.0")
+	assert.ErrorIs(t, err, git.ErrTagNotFound)
 }
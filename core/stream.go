@@ -0,0 +1,186 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	// StreamVersionPlaceholder is replaced by the version in the tag template of a stream.
+	StreamVersionPlaceholder = "{version}"
+	// defaultStreamTemplate is the tag template of the default stream.
+	defaultStreamTemplate = versionPrefix + StreamVersionPlaceholder
+	// namedStreamTemplate is the tag template of a named stream without one, after the name of the stream.
+	namedStreamTemplate = "-" + versionPrefix + StreamVersionPlaceholder
+	regexpDelimiter     = "/"
+	minRegexpLength     = 2
+)
+
+var (
+	// ErrInvalidTagFilter is returned when a tag filter is neither a glob nor a regular expression.
+	ErrInvalidTagFilter = errors.New("invalid tag filter")
+	// ErrInvalidStream is returned when the name or the tag template of a stream cannot be used.
+	ErrInvalidStream = errors.New("invalid stream")
+)
+
+// streamNamePattern matches the names of streams, which are used in tag names.
+var streamNamePattern = regexp.MustCompile(`^[\w.-]+$`)
+
+// Stream is a sequence of releases versioned independently of the other streams of the repository,
+// each tagged with the tag template of the stream. The zero value is the default stream, tagged vX.Y.Z.
+type Stream struct {
+	Name     string // Name of the stream, empty for the default stream.
+	Template string // Tag name with a {version} placeholder, such as helm-v{version}.
+}
+
+// NewStream returns the named stream with the tag template. An empty template is <name>-v{version}, and
+// an empty name is the default stream. It returns ErrInvalidStream when the name cannot be used in tag
+// names or the template does not have a single {version} placeholder.
+func NewStream(name, template string) (Stream, error) {
+	if name == "" && template == "" {
+		return Stream{}, nil
+	}
+
+	if name != "" && !streamNamePattern.MatchString(name) {
+		return Stream{}, fmt.Errorf("%w: %q is not a stream name", ErrInvalidStream, name)
+	}
+
+	if template == "" {
+		template = name + namedStreamTemplate
+	}
+
+	if strings.Count(template, StreamVersionPlaceholder) != 1 {
+		return Stream{}, fmt.Errorf("%w: %s: tag template %q must have one %s placeholder",
+			ErrInvalidStream, name, template, StreamVersionPlaceholder)
+	}
+
+	return Stream{Name: name, Template: template}, nil
+}
+
+// TagName returns the name of the tag of the version in the stream.
+func (s Stream) TagName(version string) string {
+	return strings.Replace(s.template(), StreamVersionPlaceholder, version, 1)
+}
+
+// FloatingTagName returns the name of a floating tag such as latest in the stream, prefixed by
+// the name of the stream unless it is the default stream.
+func (s Stream) FloatingTagName(name string) string {
+	if s.Name == "" {
+		return name
+	}
+
+	return s.Name + "-" + name
+}
+
+// version returns the version part of a tag name of the stream, and false when the tag is not in the stream.
+func (s Stream) version(tagName string) (string, bool) {
+	prefix, suffix, _ := strings.Cut(s.template(), StreamVersionPlaceholder)
+	if len(tagName) <= len(prefix)+len(suffix) || !strings.HasPrefix(tagName, prefix) || !strings.HasSuffix(tagName, suffix) {
+		return "", false
+	}
+
+	return tagName[len(prefix) : len(tagName)-len(suffix)], true
+}
+
+// template returns the tag template of the stream.
+func (s Stream) template() string {
+	if s.Template == "" {
+		return defaultStreamTemplate
+	}
+
+	return s.Template
+}
+
+// TagMatcher matches tag names with a glob, or with a regular expression between slashes such as /^v\d+/.
+type TagMatcher struct {
+	glob       string
+	expression *regexp.Regexp
+}
+
+// ParseTagMatcher parses a glob in the syntax of path.Match, or a regular expression between slashes.
+func ParseTagMatcher(pattern string) (TagMatcher, error) {
+	if len(pattern) > minRegexpLength && strings.HasPrefix(pattern, regexpDelimiter) &&
+		strings.HasSuffix(pattern, regexpDelimiter) {
+		expression, err := regexp.Compile(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return TagMatcher{}, fmt.Errorf("%w: %w", ErrInvalidTagFilter, err)
+		}
+
+		return TagMatcher{expression: expression}, nil
+	}
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		return TagMatcher{}, fmt.Errorf("%w: %q: %w", ErrInvalidTagFilter, pattern, err)
+	}
+
+	return TagMatcher{glob: pattern}, nil
+}
+
+// Matches reports whether the tag name matches.
+func (m TagMatcher) Matches(tagName string) bool {
+	if m.expression != nil {
+		return m.expression.MatchString(tagName)
+	}
+
+	matched, err := path.Match(m.glob, tagName)
+
+	return err == nil && matched
+}
+
+// TagFilter selects the tags considered as releases by their names.
+type TagFilter struct {
+	Include []TagMatcher // A tag must match one of them, unless there are none.
+	Exclude []TagMatcher // A tag must match none of them.
+}
+
+// ParseTagFilter parses the include and exclude patterns of a tag filter.
+func ParseTagFilter(include, exclude []string) (TagFilter, error) {
+	var (
+		filter TagFilter
+		err    error
+	)
+
+	filter.Include, err = parseTagMatchers(include)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.Exclude, err = parseTagMatchers(exclude)
+
+	return filter, err
+}
+
+// Matches reports whether the tag name is included and not excluded.
+func (f TagFilter) Matches(tagName string) bool {
+	return (len(f.Include) == 0 || matchesAny(f.Include, tagName)) && !matchesAny(f.Exclude, tagName)
+}
+
+// parseTagMatchers parses the patterns of tag matchers.
+func parseTagMatchers(patterns []string) ([]TagMatcher, error) {
+	matchers := make([]TagMatcher, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		matcher, err := ParseTagMatcher(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, matcher)
+	}
+
+	return matchers, nil
+}
+
+// matchesAny reports whether one of the matchers matches the tag name.
+func matchesAny(matchers []TagMatcher, tagName string) bool {
+	for _, matcher := range matchers {
+		if matcher.Matches(tagName) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,220 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStream(t *testing.T) {
+	t.Parallel()
+
+	stream, err := core.NewStream("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", stream.TagName("1.2.3"))
+	assert.Equal(t, "latest", stream.FloatingTagName("latest"))
+
+	stream, err = core.NewStream("helm", "")
+	require.NoError(t, err)
+	assert.Equal(t, "helm-v1.2.3", stream.TagName("1.2.3"))
+	assert.Equal(t, "helm-latest", stream.FloatingTagName("latest"))
+
+	stream, err = core.NewStream("deploy", "deploy/{version}")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy/1.2.3", stream.TagName("1.2.3"))
+
+	for _, invalid := range [][2]string{{"helm charts", ""}, {"helm", "helm"}, {"helm", "{version}-{version}"}} {
+		_, err = core.NewStream(invalid[0], invalid[1])
+		require.ErrorIs(t, err, core.ErrInvalidStream, invalid)
+	}
+}
+
+func TestTagFilter_Matches(t *testing.T) {
+	t.Parallel()
+
+	filter, err := core.ParseTagFilter([]string{"v*", `/^deploy-\d+$/`}, []string{"*-rc*"})
+	require.NoError(t, err)
+
+	assert.True(t, filter.Matches("v1.2.3"))
+	assert.True(t, filter.Matches("deploy-12"))
+	assert.False(t, filter.Matches("v1.2.3-rc.1"))
+	assert.False(t, filter.Matches("deploy-12a"))
+	assert.False(t, filter.Matches("nightly-1"))
+	assert.True(t, core.TagFilter{}.Matches("nightly-1"))
+
+	_, err = core.ParseTagFilter([]string{"v["}, nil)
+	require.ErrorIs(t, err, core.ErrInvalidTagFilter)
+
+	_, err = core.ParseTagFilter(nil, []string{"/(/"})
+	require.ErrorIs(t, err, core.ErrInvalidTagFilter)
+}
+
+func TestTagParser_ShouldOnlyParseTagsOfTheStream(t *testing.T) {
+	t.Parallel()
+
+	stream, err := core.NewStream("helm", "")
+	require.NoError(t, err)
+
+	parser := core.TagParser{Stream: stream}
+
+	version, _, err := parser.Parse("helm-v0.3.1")
+	require.NoError(t, err)
+	assert.Equal(t, "0.3.1", version.String())
+
+	_, _, err = parser.Parse("v1.2.3")
+	require.Error(t, err)
+
+	_, _, err = parser.Parse("helm-v0.3")
+	require.Error(t, err)
+
+	parser.Tolerant = true
+
+	version, normalized, err := parser.Parse("helm-v0.3")
+	require.NoError(t, err)
+	assert.True(t, normalized)
+	assert.Equal(t, "0.3.0", version.String())
+}
+
+// initStreams creates a repository with releases of the default stream, of the helm stream and of deployments,
+// with nightly tags and a fix since the last releases.
+func initStreams(t *testing.T) string {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+
+	first := gittest.CommitFile(t, repo, "feat: initial commit")
+	for _, name := range []string{"v1.0.0", "helm-v0.3.0", "deploy-7"} {
+		_, err := repo.CreateTag(name, first, nil)
+		require.NoError(t, err)
+	}
+
+	second := gittest.CommitFile(t, repo, "feat: add X")
+	_, err := repo.CreateTag("nightly-2.0.0", second, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	return path
+}
+
+func TestCalculateCommandImpl_ShouldReleaseStreamsIndependently(t *testing.T) {
+	t.Parallel()
+
+	path := initStreams(t)
+
+	helm, err := core.NewStream("helm", "")
+	require.NoError(t, err)
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetStream(helm).
+		SetAddFloatingTags(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "helm", result.Stream)
+	assert.Equal(t, "0.3.0", result.PreviousVersion)
+	assert.Equal(t, "0.4.0", result.NextVersion)
+	assert.Equal(t, []string{"helm-v0", "helm-v0.4", "helm-v0.4.0"}, result.TagsCreated)
+
+	result, err = core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetDisableTagging(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Stream)
+	assert.Equal(t, "1.0.0", result.PreviousVersion)
+	assert.Equal(t, "1.1.0", result.NextVersion)
+}
+
+func TestCalculateCommandImpl_ShouldNotReportTagsOfDeclaredStreamsAsIgnored(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		streams     map[string]core.StreamConfig
+		ignoredTags []string
+	}{
+		{nil, []string{"deploy-7", "helm-latest", "helm-v0.3.0", "nightly-2.0.0"}},
+		{
+			map[string]core.StreamConfig{"helm": {}, "deploy": {Tag: "deploy-{version}"}},
+			[]string{"nightly-2.0.0"},
+		},
+	}
+
+	for _, test := range tests {
+		path := initStreams(t)
+
+		repo, err := git.PlainOpen(path)
+		require.NoError(t, err)
+
+		head, err := repo.Head()
+		require.NoError(t, err)
+
+		_, err = repo.CreateTag("helm-latest", head.Hash(), nil)
+		require.NoError(t, err)
+
+		config := core.DefaultConfig()
+		config.TolerantTags = true
+		config.DisableTagging = true
+		config.Streams = test.streams
+
+		builder := core.NewCalculateCommandBuilder().SetPath(path)
+		require.NoError(t, config.Apply(builder))
+
+		result, err := builder.BuildCalculateCommand().Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", result.NextVersion, test.streams)
+		assert.Equal(t, test.ignoredTags, result.IgnoredTags, test.streams)
+	}
+}
+
+func TestCalculateCommandImpl_ShouldOnlyConsiderFilteredTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		include         []string
+		exclude         []string
+		previousVersion string
+	}{
+		{nil, nil, "2.0.0"},
+		{nil, []string{"[0-9]*"}, "1.0.0"},
+		{[]string{"v*"}, nil, "1.0.0"},
+		{[]string{`/^v\d+\.\d+\.\d+$/`}, nil, "1.0.0"},
+	}
+
+	for _, test := range tests {
+		path, repo := gittest.InitRepository(t)
+
+		first := gittest.CommitFile(t, repo, "feat: initial commit")
+		_, err := repo.CreateTag("v1.0.0", first, nil)
+		require.NoError(t, err)
+
+		second := gittest.CommitFile(t, repo, "feat: add X")
+		_, err = repo.CreateTag("2.0.0", second, nil)
+		require.NoError(t, err)
+
+		gittest.CommitFile(t, repo, "fix: a fix")
+
+		filter, err := core.ParseTagFilter(test.include, test.exclude)
+		require.NoError(t, err)
+
+		result, err := core.NewCalculateCommandBuilder().
+			SetPath(path).
+			SetTagFilter(filter).
+			SetDisableTagging(true).
+			BuildCalculateCommand().
+			Calculate(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, test.previousVersion, result.PreviousVersion, test)
+	}
+}
@@ -0,0 +1,232 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const tagPatternVersionGroup = "version"
+
+var (
+	// ErrInvalidTagPattern is returned when a tag pattern is not a regular expression with a capture group.
+	ErrInvalidTagPattern = errors.New("invalid tag pattern")
+	// errSkippedTag is returned when a tag is not a release of the stream, without being an ignored tag.
+	errSkippedTag = errors.New("skipped tag")
+)
+
+// floatingVersionPattern matches the versions of the major and minor floating tags, such as 1 and 1.2.
+var floatingVersionPattern = regexp.MustCompile(`^(0|[1-9]\d*)(\.(0|[1-9]\d*))?$`)
+
+// TagParser parses the names of release tags into semantic versions. Tag names are semantic versions
+// with an optional v prefix, or in the tag template of the stream; legacy tag names such as 1.2, v1,
+// v01.02.3 or release_1.2.3 are only parsed when tolerant parsing is enabled or patterns are set.
+// Tags excluded by the filter, tags outside the template of a named stream and tags of the other streams
+// are skipped, so that the tags of a stream such as helm-v1.2.3 are not legacy tags of the default stream.
+type TagParser struct {
+	Tolerant bool             // Parses legacy tag names with semver.ParseTolerant.
+	Patterns []*regexp.Regexp // Capture the version of legacy tag names, tried before tolerant parsing.
+	Floating bool             // Reserves the names of the major and minor floating tags, which are not legacy tags.
+	Filter   TagFilter        // Selects the tags considered as releases.
+	Stream   Stream           // Stream of the releases, the default stream tagged vX.Y.Z when zero.
+	Streams  []Stream         // Streams of the repository, whose release and floating tags are skipped unless they are of Stream.
+}
+
+// NormalizedTag reports a legacy tag whose name was parsed as a semantic version.
+type NormalizedTag struct {
+	Tag     string `json:"tag"`     // The name of the tag, which is never rewritten.
+	Version string `json:"version"` // The version parsed from the name.
+}
+
+// LegacyTagReporter is implemented by the Scm implementations that report the legacy tags of the repository.
+type LegacyTagReporter interface {
+	// LegacyTags returns the legacy tags that were normalized and the names of the tags that were ignored,
+	// sorted by name. Both are empty when legacy tag names are not parsed.
+	LegacyTags() ([]NormalizedTag, []string, error)
+}
+
+// NewTagParser returns the parser of the tag names, tolerant of legacy tag names when tolerant is set,
+// with the patterns capturing the version of legacy tags. It returns ErrInvalidTagPattern when a pattern is invalid.
+func NewTagParser(tolerant bool, patterns []string) (TagParser, error) {
+	parsed, err := ParseTagPatterns(patterns)
+	if err != nil {
+		return TagParser{}, err
+	}
+
+	return TagParser{Tolerant: tolerant, Patterns: parsed}, nil
+}
+
+// ParseTagPatterns compiles the tag patterns. The version is captured by the group named version,
+// or else by the first group, for example ^release_(.+)$.
+func ParseTagPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTagPattern, err)
+		}
+
+		if expression.NumSubexp() == 0 {
+			return nil, fmt.Errorf("%w: %q has no capture group for the version", ErrInvalidTagPattern, pattern)
+		}
+
+		compiled = append(compiled, expression)
+	}
+
+	return compiled, nil
+}
+
+// Parse returns the version of the tag name, and whether the name is a legacy tag name that was normalized.
+func (p TagParser) Parse(name string) (semver.Version, bool, error) {
+	if !p.Filter.Matches(name) {
+		return semver.Version{}, false, fmt.Errorf("%w: %s is excluded by the tag filters", errSkippedTag, name)
+	}
+
+	if stream, other := p.otherStream(name); other {
+		return semver.Version{}, false, fmt.Errorf("%w: %s is a tag of the %s stream", errSkippedTag, name, stream.Name)
+	}
+
+	candidate := cleanVersion(name)
+
+	if p.Stream.Template != "" {
+		var inStream bool
+
+		candidate, inStream = p.Stream.version(name)
+		if !inStream {
+			return semver.Version{}, false, fmt.Errorf("%w: %s is not a tag of the %s stream", errSkippedTag, name, p.Stream.Name)
+		}
+	}
+
+	version, err := semver.Make(candidate)
+	if err == nil || !p.legacy() {
+		return version, false, err
+	}
+
+	if p.reserved(name) {
+		return version, false, fmt.Errorf("%w: %s is reserved for a floating tag", errSkippedTag, name)
+	}
+
+	for _, pattern := range p.Patterns {
+		captured, matched := captureVersion(pattern, name)
+		if !matched {
+			continue
+		}
+
+		version, err = semver.ParseTolerant(captured)
+		if err == nil {
+			return version, true, nil
+		}
+	}
+
+	version, err = semver.ParseTolerant(candidate)
+	if err != nil {
+		return version, false, err
+	}
+
+	return version, true, nil
+}
+
+// legacy reports whether legacy tag names are parsed.
+func (p TagParser) legacy() bool {
+	return p.Tolerant || len(p.Patterns) > 0
+}
+
+// reserved reports whether the tag name is the name of a floating tag of the stream moved by the release.
+func (p TagParser) reserved(name string) bool {
+	if name == p.Stream.FloatingTagName(latestTagName) || name == p.Stream.FloatingTagName(stableTagName) {
+		return true
+	}
+
+	version, inStream := p.Stream.version(name)
+
+	return p.Floating && inStream && floatingVersionPattern.MatchString(version)
+}
+
+// otherStream returns the stream other than the stream of the parser that the tag name is a release tag or
+// a floating tag of. Streams with the tag template of the stream of the parser are not other streams.
+func (p TagParser) otherStream(name string) (Stream, bool) {
+	for _, stream := range p.Streams {
+		if stream.template() == p.Stream.template() {
+			continue
+		}
+
+		if _, inStream := stream.version(name); inStream ||
+			name == stream.FloatingTagName(latestTagName) || name == stream.FloatingTagName(stableTagName) {
+			return stream, true
+		}
+	}
+
+	return Stream{}, false
+}
+
+// captureVersion returns the version captured by the pattern in the tag name.
+func captureVersion(pattern *regexp.Regexp, name string) (string, bool) {
+	match := pattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", false
+	}
+
+	if index := pattern.SubexpIndex(tagPatternVersionGroup); index > 0 {
+		return match[index], true
+	}
+
+	return match[1], true
+}
+
+// cleanVersion removes the leading 'v' character from the given tagName if it exists.
+// It returns the cleaned tagName.
+func cleanVersion(tagName string) string {
+	if tagName != "" && tagName[0] == 'v' {
+		return tagName[1:]
+	}
+
+	return tagName
+}
+
+// LegacyTags returns the legacy tags of the repository that were normalized and the names of the tags
+// that were ignored, sorted by name. Floating tags, tags of the other streams of the parser and tags excluded
+// by the filter are neither. Nothing is reported when the tag parser does not parse legacy tag names.
+// The repository must have been opened by GetCommitLog.
+func (s *ScmGit) LegacyTags() ([]NormalizedTag, []string, error) {
+	if !s.TagParser.legacy() {
+		return nil, nil, nil
+	}
+
+	tags, err := s.Repo.Tags()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		normalized []NormalizedTag
+		ignored    []string
+	)
+
+	err = tags.ForEach(func(tag *plumbing.Reference) error {
+		name := tag.Name().Short()
+
+		version, legacy, errParse := s.TagParser.Parse(name)
+
+		if errors.Is(errParse, errSkippedTag) {
+			return nil
+		}
+
+		if errParse != nil {
+			ignored = append(ignored, name)
+		} else if legacy {
+			normalized = append(normalized, NormalizedTag{Tag: name, Version: version.String()})
+		}
+
+		return nil
+	})
+
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i].Tag < normalized[j].Tag })
+	sort.Strings(ignored)
+
+	return normalized, ignored, err
+}
@@ -0,0 +1,146 @@
+package core_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/martoc/semver/core"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	patterns, err := core.ParseTagPatterns([]string{`^release_(.+)$`, `^build-(?P<version>\d+\.\d+)-final$`})
+	require.NoError(t, err)
+
+	strict := core.TagParser{}
+	tolerant := core.TagParser{Tolerant: true, Patterns: patterns}
+	floating := core.TagParser{Tolerant: true, Floating: true}
+
+	tests := []struct {
+		parser     core.TagParser
+		name       string
+		version    string
+		normalized bool
+		valid      bool
+	}{
+		{strict, "v1.2.3", "1.2.3", false, true},
+		{strict, "1.2.3", "1.2.3", false, true},
+		{strict, "1.2", "", false, false},
+		{tolerant, "v1.2.3", "1.2.3", false, true},
+		{tolerant, "1.2", "1.2.0", true, true},
+		{tolerant, "v1", "1.0.0", true, true},
+		{tolerant, "v01.02.3", "1.2.3", true, true},
+		{tolerant, "release_1.2.3", "1.2.3", true, true},
+		{tolerant, "build-4.5-final", "4.5.0", true, true},
+		{tolerant, "nightly", "", false, false},
+		{tolerant, "latest", "", false, false},
+		{floating, "v1", "", false, false},
+		{floating, "v1.2", "", false, false},
+		{floating, "v01", "1.0.0", true, true},
+	}
+
+	for _, test := range tests {
+		version, normalized, err := test.parser.Parse(test.name)
+		if !test.valid {
+			require.Error(t, err, test.name)
+
+			continue
+		}
+
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.version, version.String(), test.name)
+		assert.Equal(t, test.normalized, normalized, test.name)
+	}
+}
+
+func TestParseTagPatterns_ShouldRejectInvalidPatterns(t *testing.T) {
+	t.Parallel()
+
+	_, err := core.ParseTagPatterns([]string{`^release_(`})
+	require.ErrorIs(t, err, core.ErrInvalidTagPattern)
+
+	_, err = core.ParseTagPatterns([]string{`^release_.+$`})
+	require.ErrorIs(t, err, core.ErrInvalidTagPattern)
+}
+
+// initLegacyTags creates a repository tagged v1, v01.02.3 and release_1.4.0 by an older release process,
+// with an unrelated nightly tag and a fix since the last release.
+func initLegacyTags(t *testing.T) (string, plumbing.Hash) {
+	t.Helper()
+
+	path, repo := gittest.InitRepository(t)
+
+	first := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1", first, nil)
+	require.NoError(t, err)
+
+	second := gittest.CommitFile(t, repo, "feat: add X")
+	_, err = repo.CreateTag("v01.02.3", second, nil)
+	require.NoError(t, err)
+
+	third := gittest.CommitFile(t, repo, "feat: add Y")
+	_, err = repo.CreateTag("release_1.4.0", third, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("nightly", third, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	return path, first
+}
+
+func TestCalculateCommandImpl_ShouldIgnoreLegacyTagsByDefault(t *testing.T) {
+	t.Parallel()
+
+	path, _ := initLegacyTags(t)
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetDisableTagging(true).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, result.PreviousVersion)
+	assert.Empty(t, result.NormalizedTags)
+	assert.Empty(t, result.IgnoredTags)
+}
+
+func TestCalculateCommandImpl_ShouldCalculateFromNormalizedLegacyTags(t *testing.T) {
+	t.Parallel()
+
+	path, first := initLegacyTags(t)
+
+	patterns, err := core.ParseTagPatterns([]string{`^release_(.+)$`})
+	require.NoError(t, err)
+
+	result, err := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetTagParser(core.TagParser{Tolerant: true, Patterns: patterns}).
+		BuildCalculateCommand().
+		Calculate(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", result.PreviousVersion)
+	assert.Equal(t, "1.4.1", result.NextVersion)
+	assert.Equal(t, []string{"v1.4.1"}, result.TagsCreated)
+	assert.Equal(t, []core.NormalizedTag{
+		{Tag: "release_1.4.0", Version: "1.4.0"},
+		{Tag: "v01.02.3", Version: "1.2.3"},
+		{Tag: "v1", Version: "1.0.0"},
+	}, result.NormalizedTags)
+	assert.Equal(t, []string{"nightly"}, result.IgnoredTags)
+
+	repo, err := git.PlainOpen(path)
+	require.NoError(t, err)
+
+	legacy, err := repo.Tag("v1")
+	require.NoError(t, err)
+	assert.Equal(t, first, legacy.Hash())
+}
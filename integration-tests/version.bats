@@ -8,4 +8,11 @@ load 'common.sh'
   run $BINARY_PATH version
   assert_success
   assert_equal $TAG_VERSION $(echo $output | jq -r '.version')
+  assert_equal "semver/v1" $(echo $output | jq -r '.apiVersion')
+}
+
+@test "Print output schema" {
+  run $BINARY_PATH schema calculate
+  assert_success
+  assert_equal "semver/v1" $(echo $output | jq -r '.properties.apiVersion.const')
 }
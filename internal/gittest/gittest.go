@@ -0,0 +1,71 @@
+// Package gittest creates the git repositories used as fixtures by the tests of the other packages.
+package gittest
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+)
+
+// FixtureFileName is the file changed by every commit of CommitFile.
+const FixtureFileName = "file.txt"
+
+const fixtureFilePermissions = 0o600
+
+// InitRepository creates a git repository with a working tree in a temporary directory.
+func InitRepository(t *testing.T) (string, *git.Repository) {
+	t.Helper()
+
+	path := t.TempDir()
+
+	repo, err := git.PlainInit(path, false)
+	require.NoError(t, err)
+
+	return path, repo
+}
+
+// CommitFile appends a line to the fixture file and commits it with the given message.
+func CommitFile(t *testing.T, repo *git.Repository, message string) plumbing.Hash {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	path := filepath.Join(worktree.Filesystem.Root(), FixtureFileName)
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fixtureFilePermissions)
+	require.NoError(t, err)
+
+	_, err = file.WriteString(strconv.FormatInt(time.Now().UnixNano(), 10) + "\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = worktree.Add(FixtureFileName)
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: "Sarah Connor", Email: "sarah@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	return hash
+}
+
+// CloneBare creates a bare copy of the repository at sourcePath to act as a remote.
+func CloneBare(t *testing.T, sourcePath string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "remote.git")
+
+	_, err := git.PlainClone(path, true, &git.CloneOptions{URL: sourcePath, Tags: git.AllTags})
+	require.NoError(t, err)
+
+	return path
+}
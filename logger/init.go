@@ -1,31 +1,138 @@
+// Package logger provides the shared logger of the semver command.
+// The logger writes text to the standard error at the info level until it is configured.
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
-var log = logrus.New()
-
-var file *os.File
+// Log formats supported by Configure.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
 
-const readWrite = 0o666
+const logFileMode = 0o600
 
-func init() {
-	file, _ = os.OpenFile("semver.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, readWrite)
+var (
+	// ErrInvalidLevel is returned when the log level is not a logrus level such as debug, info or warn.
+	ErrInvalidLevel = errors.New("invalid log level")
+	// ErrInvalidFormat is returned when the log format is neither text nor json.
+	ErrInvalidFormat = errors.New("invalid log format")
+)
 
-	log.SetFormatter(&logrus.TextFormatter{
-		FullTimestamp: true,
-	})
+var (
+	mu   sync.Mutex
+	log  *logrus.Logger
+	file *os.File
+)
 
-	log.SetOutput(file)
+// Options configures the shared logger.
+type Options struct {
+	Level  string // Minimum level of the logged entries, info when empty.
+	Format string // Format of the entries, text or json, text when empty.
+	File   string // Path of a file the entries are appended to instead of the standard error.
 }
 
+// GetInstance returns the shared logger, creating it on first use.
 func GetInstance() *logrus.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if log == nil {
+		log = logrus.New()
+		log.SetOutput(os.Stderr)
+		log.SetFormatter(textFormatter())
+	}
+
 	return log
 }
 
+// Configure sets the level, format and output of the shared logger. A log file is created
+// if it does not exist and is only readable by the current user.
+// It returns an error if the level or format is invalid or the file cannot be opened, leaving the logger unchanged.
+func Configure(options Options) error {
+	level := logrus.InfoLevel
+
+	if options.Level != "" {
+		var err error
+
+		level, err = logrus.ParseLevel(options.Level)
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidLevel, options.Level)
+		}
+	}
+
+	var formatter logrus.Formatter
+
+	switch options.Format {
+	case "", FormatText:
+		formatter = textFormatter()
+	case FormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return fmt.Errorf("%w: %q, expected %s or %s", ErrInvalidFormat, options.Format, FormatText, FormatJSON)
+	}
+
+	var output io.Writer = os.Stderr
+
+	var logFile *os.File
+
+	if options.File != "" {
+		var err error
+
+		logFile, err = os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, logFileMode)
+		if err != nil {
+			return err
+		}
+
+		output = logFile
+	}
+
+	instance := GetInstance()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if file != nil {
+		file.Close()
+	}
+
+	file = logFile
+
+	instance.SetLevel(level)
+	instance.SetFormatter(formatter)
+	instance.SetOutput(output)
+
+	return nil
+}
+
+// Close closes the log file, if any, and restores logging to the standard error.
 func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if file == nil {
+		return
+	}
+
+	if log != nil {
+		log.SetOutput(os.Stderr)
+	}
+
 	file.Close()
+	file = nil
+}
+
+// textFormatter returns the formatter of the text format.
+func textFormatter() logrus.Formatter {
+	return &logrus.TextFormatter{
+		FullTimestamp: true,
+	}
 }
@@ -0,0 +1,41 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/martoc/semver/logger"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure_ShouldRejectInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	assert.ErrorIs(t, logger.Configure(logger.Options{Level: "loud"}), logger.ErrInvalidLevel)
+	assert.ErrorIs(t, logger.Configure(logger.Options{Format: "xml"}), logger.ErrInvalidFormat)
+}
+
+func TestConfigure_ShouldWriteJSONToLogFile(t *testing.T) { //nolint:paralleltest // Configures the shared logger
+	path := filepath.Join(t.TempDir(), "semver.log")
+
+	require.NoError(t, logger.Configure(logger.Options{Level: "debug", Format: logger.FormatJSON, File: path}))
+	t.Cleanup(func() {
+		logger.Close()
+		require.NoError(t, logger.Configure(logger.Options{}))
+	})
+
+	assert.Equal(t, logrus.DebugLevel, logger.GetInstance().GetLevel())
+
+	logger.GetInstance().Debug("deepening")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"msg":"deepening"`)
+}
@@ -0,0 +1,417 @@
+// Package semver calculates the next semantic version of a git repository from its conventional commits
+// and tags the release. It is the stable library interface of the semver command.
+package semver
+
+import (
+	"context"
+	"time"
+
+	"github.com/martoc/semver/core"
+	"github.com/sirupsen/logrus"
+)
+
+// APIVersion is the version of the JSON encoding of Result.
+const APIVersion = core.APIVersion
+
+var (
+	// ErrNoCommits is returned when the repository has no commit to calculate a version for.
+	ErrNoCommits = core.ErrNoCommits
+	// ErrDirtyTree is returned when Options.RequireClean is set and the working tree has uncommitted changes.
+	ErrDirtyTree = core.ErrDirtyTree
+	// ErrTagExists is returned when the release tag already exists on another commit, locally or on a remote,
+	// and no untagged version was found within the release attempts.
+	ErrTagExists = core.ErrTagConflict
+	// ErrInvalidDescribeFormat is returned when DescribeOptions.Format is neither dev nor git.
+	ErrInvalidDescribeFormat = core.ErrInvalidDescribeFormat
+)
+
+// Credentials authenticate against the remotes of the repository.
+type Credentials struct {
+	Username            string // HTTP basic authentication user name.
+	Password            string // HTTP basic authentication password.
+	Token               string // HTTP access token, sent as the basic authentication password.
+	SSHKeyFile          string // Path of the private key used for SSH remotes.
+	SSHKeyPassphrase    string // Passphrase of the SSH private key.
+	SSHAgent            bool   // Use the SSH agent.
+	UseCredentialHelper bool   // Ask the configured git credential helper for HTTP credentials.
+}
+
+// MergeMessageAdapter extracts the conventional commit message from a merge message of a forge.
+type MergeMessageAdapter interface {
+	// Adapt returns the conventional commit message and true when the message is in the format of the adapter,
+	// or false when it is not. The conventional commit message is empty when the merge message does not
+	// have one, so that only the merged commits are analyzed.
+	Adapt(commitMessage string) (string, bool)
+}
+
+// PushResult reports the outcome of pushing a single tag to a remote.
+type PushResult struct {
+	Remote string `json:"remote"`
+	Ref    string `json:"ref"`
+	Forced bool   `json:"forced"`
+	Status string `json:"status"` // One of pushed, up-to-date, rejected or skipped.
+	Error  string `json:"error,omitempty"`
+}
+
+// RollbackResult reports the outcome of restoring a single tag after a failed release.
+type RollbackResult struct {
+	Ref    string `json:"ref"`
+	Status string `json:"status"` // One of restored, deleted or failed.
+	Hash   string `json:"hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// TagChange reports a local tag changed by fetching the tags of the remote.
+type TagChange struct {
+	Ref      string `json:"ref"`
+	Status   string `json:"status"` // One of added, updated or pruned.
+	Hash     string `json:"hash,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// NormalizedTag reports a legacy tag parsed as a version.
+type NormalizedTag struct {
+	Tag     string `json:"tag"`
+	Version string `json:"version"`
+}
+
+// Description is the development version of HEAD returned by Describe.
+type Description struct {
+	APIVersion      string `json:"apiVersion"`
+	Version         string `json:"version"`
+	NextVersion     string `json:"next_version"`
+	PreviousVersion string `json:"previous_version,omitempty"`
+	Stream          string `json:"stream,omitempty"`
+	Distance        int    `json:"distance"`
+	Hash            string `json:"hash"`
+	Dirty           bool   `json:"dirty"`
+}
+
+// Options configures the version calculation. The zero value calculates and tags the next version
+// of the repository in the current directory without pushing.
+type Options struct {
+	Path            string       // Path inside the git repository, defaults to the current directory.
+	RepositoryURL   string       // URL of a repository to clone into memory instead of using Path.
+	Remotes         []string     // Remotes to push tags to, the first one is also used for fetching.
+	Credentials     *Credentials // Credentials for the remotes, read from the environment when nil.
+	Fetch           bool         // Fetch the tags of the first remote before calculating.
+	Prune           bool         // Fetch the tags and delete local tags deleted from the remote.
+	Deepen          bool         // Fetch more history when a shallow clone has no release tag.
+	FirstParent     bool         // Only analyze the first parent of each commit.
+	MergeAware      bool         // Analyze the first parents and the commits merged by each merge commit.
+	Push            bool         // Push the tags created by the release.
+	DisableTagging  bool         // Calculate the version without creating any tag.
+	RequireClean    bool         // Fail with ErrDirtyTree instead of tagging a working tree with uncommitted changes.
+	AddFloatingTags bool         // Move the vX and vX.Y floating tags to the new version.
+	AddLatestTag    bool         // Move the latest tag to the new version.
+	AddStableTag    bool         // Move the stable tag to the new version.
+	Branch          string       // Branch name used to match branch rules, detected when empty.
+	BranchRules     []string     // Branch rules such as "develop:prerelease=beta", the first match wins.
+	Line            string       // Version line to release within, for example "1.x".
+	BaseTag         string       // Base tag policy: greatest-reachable when empty, nearest or greatest.
+	TolerantTags    bool         // Parse legacy tags such as 1.2, v1 or v01.02.3 as versions.
+	TagPatterns     []string     // Regular expressions capturing the version of legacy tags, such as "^release_(.+)$".
+	TagInclude      []string     // Globs or /regular expressions/ of the tags considered as releases, every tag when empty.
+	TagExclude      []string     // Globs or /regular expressions/ of the tags never considered as releases.
+	Stream          string       // Release stream with its own tags and versions, the default stream when empty.
+	StreamTag       string       // Tag template of the stream such as "helm-v{version}", <stream>-v{version} or v{version} when empty.
+	// Types maps each commit type to the version component it increments, major, minor, patch or none,
+	// such as {"feat": "minor", "chore": "none"}. The default commit types are used when empty.
+	Types map[string]string
+	// Files are the files the released version is written to. Files and Changelog need a Path inside
+	// a repository with a working tree.
+	Files []File
+	// Changelog is the changelog the commits of each release are added to, none when nil.
+	Changelog *Changelog
+	// MergeAdapters extract the conventional commit messages from merge messages, in order.
+	// The adapters of Azure DevOps, GitHub, GitLab and Bitbucket are used when nil.
+	MergeAdapters []MergeMessageAdapter
+	// Logger receives the progress and ignored errors, the shared logger writing to the standard error when nil.
+	Logger logrus.FieldLogger
+}
+
+// File is a file of the repository the released version is written to, such as VERSION or package.json.
+type File struct {
+	Path    string // Path of the file relative to the repository root.
+	Pattern string // Regular expression whose first group is replaced by the version, the whole file when empty.
+}
+
+// Changelog adds the commits of each release to a markdown file, newest release first.
+type Changelog struct {
+	File     string             // Path relative to the repository root, CHANGELOG.md when empty.
+	Sections []ChangelogSection // Sections of a release in order, the feat, fix and perf types when empty.
+}
+
+// ChangelogSection lists the commits of a conventional commit type under a heading.
+type ChangelogSection struct {
+	Type  string // Conventional commit type listed in the section.
+	Title string // Heading of the section.
+}
+
+// DescribeOptions configures the development version. The tagging and pushing options are not used.
+type DescribeOptions struct {
+	Options
+	Format    string // Format of the version: dev for 1.4.0-dev.7+g1a2b3c4 when empty, or git for 1.3.2-7-g1a2b3c4.
+	DirtyMark string // Appended when the working tree has uncommitted changes, which are not checked when empty.
+}
+
+// Commit is a commit released by the next version.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+}
+
+// Result is the outcome of a version calculation. When no commit since the previous version requires
+// a release, Released is false, NextVersion is the previous version and no tag is created or pushed.
+type Result struct {
+	APIVersion          string           `json:"apiVersion"`
+	PreviousVersion     string           `json:"previous_version,omitempty"`
+	NextVersion         string           `json:"next_version"`
+	Stream              string           `json:"stream,omitempty"`
+	Bump                string           `json:"bump,omitempty"`
+	Released            bool             `json:"released"`
+	Branch              string           `json:"branch,omitempty"`
+	Commits             []Commit         `json:"commits,omitempty"`
+	TagsCreated         []string         `json:"tags_created,omitempty"`
+	FloatingTagsMoved   []string         `json:"floating_tags_moved,omitempty"`
+	FloatingTagsSkipped []string         `json:"floating_tags_skipped,omitempty"`
+	Fetched             []TagChange      `json:"fetched,omitempty"`
+	Pushed              []PushResult     `json:"pushed,omitempty"`
+	RolledBack          []RollbackResult `json:"rolled_back,omitempty"`
+	UnreachableTags     []string         `json:"unreachable_tags,omitempty"`
+	NormalizedTags      []NormalizedTag  `json:"normalized_tags,omitempty"`
+	IgnoredTags         []string         `json:"ignored_tags,omitempty"`
+	FilesUpdated        []string         `json:"files_updated,omitempty"` // Files the version or the changelog was written to.
+}
+
+// Calculate calculates the next version of the repository and releases it as configured by the options.
+// When the release fails after tags were created or pushed, the partial result is returned with the error,
+// so that the pushed and rolled back tags can be reported. Canceling the context stops cloning, fetching,
+// walking the history and pushing, and the tags created so far are rolled back before the context error
+// is returned.
+func Calculate(ctx context.Context, opts Options) (*Result, error) {
+	command, err := newCalculateCommand(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := command.Calculate(ctx)
+	if output == nil {
+		return nil, err
+	}
+
+	return newResult(output), err
+}
+
+// Describe returns the development version of HEAD, based on the next version calculated as configured by
+// the options and on the number of commits since the previous version, without creating or pushing any tag.
+func Describe(ctx context.Context, opts DescribeOptions) (*Description, error) {
+	format, err := core.ParseDescribeFormat(opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	builder, err := newCalculateBuilder(opts.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := core.NewDescribeCommandBuilder(builder).
+		SetFormat(format).
+		SetDirtyMark(opts.DirtyMark).
+		BuildDescribeCommand().
+		Describe(ctx)
+	if output == nil {
+		return nil, err
+	}
+
+	return &Description{
+		APIVersion:      output.APIVersion,
+		Version:         output.Version,
+		NextVersion:     output.NextVersion,
+		PreviousVersion: output.PreviousVersion,
+		Stream:          output.Stream,
+		Distance:        output.Distance,
+		Hash:            output.Hash,
+		Dirty:           output.Dirty,
+	}, err
+}
+
+// newCalculateCommand creates the calculate command configured by the options.
+func newCalculateCommand(opts Options) (*core.CalculateCommandImpl, error) {
+	builder, err := newCalculateBuilder(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.BuildCalculateCommand(), nil
+}
+
+// newCalculateBuilder creates the builder of the calculate command configured by the options. The settings
+// shared with the configuration file are parsed as a core.Config, so that they are validated alike.
+func newCalculateBuilder(opts Options) (*core.CalculateCommandBuilder, error) {
+	config, err := newConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	path := opts.Path
+	if path == "" {
+		path = "."
+	}
+
+	builder := core.NewCalculateCommandBuilder().
+		SetPath(path).
+		SetRepositoryURL(opts.RepositoryURL).
+		SetBranch(opts.Branch).
+		SetMergeAdapters(mergeAdapters(opts.MergeAdapters)).
+		SetLogger(opts.Logger)
+	if opts.Credentials != nil {
+		builder.SetCredentials(coreCredentials(*opts.Credentials))
+	}
+
+	err = config.Apply(builder)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder, nil
+}
+
+// newConfig converts the options shared with the configuration file.
+func newConfig(opts Options) (core.Config, error) {
+	branchRules, err := core.ParseBranchRuleConfigs(opts.BranchRules)
+	if err != nil {
+		return core.Config{}, err
+	}
+
+	config := core.Config{
+		Push:            opts.Push,
+		Fetch:           opts.Fetch,
+		Prune:           opts.Prune,
+		Deepen:          opts.Deepen,
+		FirstParent:     opts.FirstParent,
+		MergeAware:      opts.MergeAware,
+		DisableTagging:  opts.DisableTagging,
+		RequireClean:    opts.RequireClean,
+		AddFloatingTags: opts.AddFloatingTags,
+		AddLatestTag:    opts.AddLatestTag,
+		AddStableTag:    opts.AddStableTag,
+		TolerantTags:    opts.TolerantTags,
+		Remotes:         opts.Remotes,
+		Line:            opts.Line,
+		BaseTag:         opts.BaseTag,
+		TagPatterns:     opts.TagPatterns,
+		TagInclude:      opts.TagInclude,
+		TagExclude:      opts.TagExclude,
+		TagTemplate:     opts.StreamTag,
+		Stream:          opts.Stream,
+		BranchRules:     branchRules,
+		Types:           opts.Types,
+		Files:           convertAll(opts.Files, func(file File) core.FileConfig { return core.FileConfig(file) }),
+	}
+
+	if opts.Stream != "" {
+		config.TagTemplate = ""
+		config.Streams = map[string]core.StreamConfig{opts.Stream: {Tag: opts.StreamTag}}
+	}
+
+	if opts.Changelog != nil {
+		config.Changelog = &core.ChangelogConfig{
+			File: opts.Changelog.File,
+			Sections: convertAll(opts.Changelog.Sections, func(section ChangelogSection) core.ChangelogSectionConfig {
+				return core.ChangelogSectionConfig(section)
+			}),
+		}
+	}
+
+	return config, nil
+}
+
+// newResult converts the output of the calculate command.
+func newResult(output *core.CalculateOutput) *Result {
+	commits := make([]Commit, 0, len(output.Commits))
+	for _, commit := range output.Commits {
+		commits = append(commits, Commit{
+			Hash:    commit.Hash,
+			Message: commit.Message,
+			Author:  commit.Author,
+			Date:    commit.Date,
+		})
+	}
+
+	return &Result{
+		APIVersion:          output.APIVersion,
+		PreviousVersion:     output.PreviousVersion,
+		NextVersion:         output.NextVersion,
+		Stream:              output.Stream,
+		Bump:                output.Bump,
+		Released:            output.Released,
+		Branch:              output.Branch,
+		Commits:             commits,
+		TagsCreated:         output.TagsCreated,
+		FloatingTagsMoved:   output.FloatingTagsMoved,
+		FloatingTagsSkipped: output.FloatingTagsSkipped,
+		Fetched:             convertAll(output.Fetched, newTagChange),
+		Pushed:              convertAll(output.Pushed, newPushResult),
+		RolledBack:          convertAll(output.RolledBack, newRollbackResult),
+		UnreachableTags:     output.UnreachableTags,
+		NormalizedTags:      convertAll(output.NormalizedTags, newNormalizedTag),
+		IgnoredTags:         output.IgnoredTags,
+		FilesUpdated:        output.FilesUpdated,
+	}
+}
+
+// newTagChange converts a tag changed by fetching.
+func newTagChange(change core.TagChange) TagChange {
+	return TagChange{Ref: change.Ref, Status: change.Status, Hash: change.Hash, Previous: change.Previous}
+}
+
+// newPushResult converts the outcome of pushing a tag.
+func newPushResult(result core.PushResult) PushResult {
+	return PushResult{Remote: result.Remote, Ref: result.Ref, Forced: result.Forced, Status: result.Status, Error: result.Error}
+}
+
+// newRollbackResult converts the outcome of restoring a tag.
+func newRollbackResult(result core.RollbackResult) RollbackResult {
+	return RollbackResult{Ref: result.Ref, Status: result.Status, Hash: result.Hash, Error: result.Error}
+}
+
+// newNormalizedTag converts a legacy tag parsed as a version.
+func newNormalizedTag(tag core.NormalizedTag) NormalizedTag {
+	return NormalizedTag{Tag: tag.Tag, Version: tag.Version}
+}
+
+// coreCredentials converts the credentials.
+func coreCredentials(credentials Credentials) core.Credentials {
+	return core.Credentials{
+		Username:            credentials.Username,
+		Password:            credentials.Password,
+		Token:               credentials.Token,
+		SSHKeyFile:          credentials.SSHKeyFile,
+		SSHKeyPassphrase:    credentials.SSHKeyPassphrase,
+		SSHAgent:            credentials.SSHAgent,
+		UseCredentialHelper: credentials.UseCredentialHelper,
+	}
+}
+
+// mergeAdapters converts the merge message adapters, keeping nil for the default adapters.
+func mergeAdapters(adapters []MergeMessageAdapter) core.MergeMessageAdapters {
+	return convertAll(adapters, func(adapter MergeMessageAdapter) core.MergeMessageAdapter { return adapter })
+}
+
+// convertAll converts every item, keeping nil for nil.
+func convertAll[T, U any](items []T, convert func(T) U) []U {
+	if items == nil {
+		return nil
+	}
+
+	converted := make([]U, 0, len(items))
+	for _, item := range items {
+		converted = append(converted, convert(item))
+	}
+
+	return converted
+}
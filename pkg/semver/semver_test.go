@@ -0,0 +1,257 @@
+package semver_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/martoc/semver/internal/gittest"
+	"github.com/martoc/semver/pkg/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculate_ShouldReturnRelease(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	fix := gittest.CommitFile(t, repo, "fix: a fix")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{Path: path, AddFloatingTags: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, semver.APIVersion, result.APIVersion)
+	assert.True(t, result.Released)
+	assert.Equal(t, "1.0.0", result.PreviousVersion)
+	assert.Equal(t, "1.0.1", result.NextVersion)
+	assert.Equal(t, "patch", result.Bump)
+	assert.Equal(t, []string{"v1", "v1.0", "v1.0.1"}, result.TagsCreated)
+	require.Len(t, result.Commits, 1)
+	assert.Equal(t, fix.String(), result.Commits[0].Hash)
+	assert.Equal(t, "fix: a fix", result.Commits[0].Message)
+	assert.Equal(t, "Sarah Connor", result.Commits[0].Author)
+
+	tag, err := repo.Tag("v1.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, fix, tag.Hash())
+}
+
+func TestCalculate_ShouldReportPushedTags(t *testing.T) {
+	t.Parallel()
+
+	sourcePath, source := gittest.InitRepository(t)
+
+	gittest.CommitFile(t, source, "feat: initial commit")
+
+	remotePath := gittest.CloneBare(t, sourcePath)
+
+	path := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainClone(path, false, &git.CloneOptions{URL: remotePath})
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, clone, "fix: a fix")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{
+		Path:        path,
+		Push:        true,
+		Credentials: &semver.Credentials{},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []semver.PushResult{{Remote: "origin", Ref: "refs/tags/v0.1.0", Status: "pushed"}}, result.Pushed)
+}
+
+// releaseAdapter adapts messages such as "Release: feat: add X" of a custom merge tool.
+type releaseAdapter struct{}
+
+func (releaseAdapter) Adapt(commitMessage string) (string, bool) {
+	return strings.CutPrefix(commitMessage, "Release: ")
+}
+
+func TestCalculate_ShouldUseMergeAdapters(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	gittest.CommitFile(t, repo, "Release: feat: add X")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{
+		Path:           path,
+		DisableTagging: true,
+		MergeAdapters:  []semver.MergeMessageAdapter{releaseAdapter{}},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "minor", result.Bump)
+}
+
+func TestCalculate_ShouldUseCommitTypes(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+	gittest.CommitFile(t, repo, "deps: bump go-git")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{
+		Path:  path,
+		Types: map[string]string{"fix": "none", "deps": "minor"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "minor", result.Bump)
+	assert.Equal(t, "1.1.0", result.NextVersion)
+
+	_, err = semver.Calculate(context.Background(), semver.Options{Path: path, Types: map[string]string{"feat": "huge"}})
+	require.Error(t, err)
+}
+
+func TestCalculate_ShouldUpdateFilesAndChangelog(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "feat(api): add search")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{
+		Path:  path,
+		Files: []semver.File{{Path: "VERSION"}},
+		Changelog: &semver.Changelog{
+			File:     "CHANGES.md",
+			Sections: []semver.ChangelogSection{{Type: "feat", Title: "New"}},
+		},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VERSION", "CHANGES.md"}, result.FilesUpdated)
+
+	version, err := os.ReadFile(filepath.Join(path, "VERSION"))
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0\n", string(version))
+
+	changelog, err := os.ReadFile(filepath.Join(path, "CHANGES.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(changelog), "### New\n\n- **api:** add search")
+}
+
+func TestCalculate_ShouldReleaseStream(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v3.0.0", base, nil)
+	require.NoError(t, err)
+	_, err = repo.CreateTag("chart-1.2.0", base, nil)
+	require.NoError(t, err)
+
+	gittest.CommitFile(t, repo, "fix: a fix")
+
+	result, err := semver.Calculate(context.Background(), semver.Options{
+		Path:      path,
+		Stream:    "helm",
+		StreamTag: "chart-{version}",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", result.PreviousVersion)
+	assert.Equal(t, []string{"chart-1.2.1"}, result.TagsCreated)
+}
+
+func TestDescribe_ShouldReturnDevelopmentVersionWithoutTagging(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	base := gittest.CommitFile(t, repo, "feat: initial commit")
+	_, err := repo.CreateTag("v1.0.0", base, nil)
+	require.NoError(t, err)
+
+	fix := gittest.CommitFile(t, repo, "fix: a fix")
+
+	description, err := semver.Describe(context.Background(), semver.DescribeOptions{
+		Options: semver.Options{Path: path, AddFloatingTags: true, Push: true},
+		Format:  "git",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0-1-g"+fix.String()[:7], description.Version)
+	assert.Equal(t, "1.0.1", description.NextVersion)
+
+	_, err = repo.Tag("v1.0.1")
+	require.ErrorIs(t, err, git.ErrTagNotFound)
+
+	_, err = semver.Describe(context.Background(), semver.DescribeOptions{Options: semver.Options{Path: path}, Format: "short"})
+	require.ErrorIs(t, err, semver.ErrInvalidDescribeFormat)
+}
+
+func TestCalculate_ShouldFailWithoutCommits(t *testing.T) {
+	t.Parallel()
+
+	path, _ := gittest.InitRepository(t)
+
+	result, err := semver.Calculate(context.Background(), semver.Options{Path: path})
+
+	assert.ErrorIs(t, err, semver.ErrNoCommits)
+	assert.Nil(t, result)
+}
+
+func TestCalculate_ShouldFailIfTreeIsDirtyAndCleanTreeIsRequired(t *testing.T) {
+	t.Parallel()
+
+	path, repo := gittest.InitRepository(t)
+
+	gittest.CommitFile(t, repo, "feat: initial commit")
+	require.NoError(t, os.WriteFile(filepath.Join(path, gittest.FixtureFileName), []byte("changed"), 0o600))
+
+	_, err := semver.Calculate(context.Background(), semver.Options{Path: path, RequireClean: true})
+
+	assert.ErrorIs(t, err, semver.ErrDirtyTree)
+
+	tags, err := repo.Tags()
+	require.NoError(t, err)
+
+	count := 0
+	require.NoError(t, tags.ForEach(func(*plumbing.Reference) error {
+		count++
+
+		return nil
+	}))
+	assert.Zero(t, count)
+}
+
+func TestCalculate_ShouldFailWithInvalidBranchRule(t *testing.T) {
+	t.Parallel()
+
+	_, err := semver.Calculate(context.Background(), semver.Options{BranchRules: []string{"main:max-bump=huge"}})
+
+	assert.Error(t, err)
+}
+
+func TestCalculate_ShouldNotStartIfContextIsCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := semver.Calculate(ctx, semver.Options{})
+
+	assert.ErrorIs(t, err, context.Canceled)
+}
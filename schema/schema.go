@@ -0,0 +1,52 @@
+// Package schema provides the JSON Schemas of the configuration file and of the JSON output of the commands.
+// The schemas of each API version are kept in a directory named after the version.
+package schema
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Version is the API version of the embedded schemas.
+const Version = "v1"
+
+const schemaExtension = ".json"
+
+// ErrUnknownSchema is returned when no schema has the requested name.
+var ErrUnknownSchema = errors.New("unknown schema")
+
+//go:embed v1/*.json
+var files embed.FS
+
+// Names returns the names of the schemas in alphabetical order.
+func Names() []string {
+	entries, err := fs.ReadDir(files, Version)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), schemaExtension))
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the schema with the name, for example calculate or config.
+func Get(name string) ([]byte, error) {
+	names := Names()
+	if !slices.Contains(names, name) {
+		return nil, fmt.Errorf("%w: %q, expected one of %s", ErrUnknownSchema, name, strings.Join(names, ", "))
+	}
+
+	return files.ReadFile(path.Join(Version, name+schemaExtension))
+}
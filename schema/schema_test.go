@@ -0,0 +1,40 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/martoc/semver/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNames_ShouldListEmbeddedSchemas(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"calculate", "config", "config-validate", "describe", "error", "version"}, schema.Names())
+}
+
+func TestGet_ShouldReturnVersionedSchemas(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range schema.Names() {
+		content, err := schema.Get(name)
+		require.NoError(t, err)
+
+		var document struct {
+			ID string `json:"$id"`
+		}
+
+		require.NoError(t, json.Unmarshal(content, &document), name)
+		assert.Equal(t, "https://github.com/martoc/semver/schema/"+schema.Version+"/"+name+".json", document.ID)
+	}
+}
+
+func TestGet_ShouldRejectUnknownSchema(t *testing.T) {
+	t.Parallel()
+
+	_, err := schema.Get("../v1/calculate")
+
+	assert.ErrorIs(t, err, schema.ErrUnknownSchema)
+}